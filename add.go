@@ -1,56 +1,164 @@
 package schedule
 
 import (
+	"fmt"
 	"time"
 )
 
-// AddDate performs date arithmetic accounting for dates excluded by schedules
+// AddDate performs date arithmetic accounting for dates excluded by schedules.
+// Only excludes covering the entire day are skipped, including excludes
+// scoped to weekdays.  Dates are evaluated in the location of the schedules,
+// if any.  If the excludes cover a year of consecutive dates, the date reached
+// is returned; see AddDateChecked.  See AddBusinessDays to count only dates
+// the schedules are open
 func AddDate(t time.Time, days int, ss ...Schedule) time.Time {
-	type DateRange struct {
-		From string
-		To   string
-	}
+	date, _ := AddDateChecked(t, days, ss...)
+	return date
+}
 
-	var excludes []DateRange
+// AddDateChecked is AddDate returning an error wrapping ErrNoAvailability,
+// along with the date reached, if the excludes cover a year of consecutive
+// dates
+func AddDateChecked(t time.Time, days int, ss ...Schedule) (time.Time, error) {
+	var excludes []Schedule
 	for _, s := range ss {
-		if !s.IsExclude() {
-			continue
-		}
-
-		dateFrom, ok := s.DateFrom()
-		if !ok {
-			continue
+		if s.ExcludesAllDay() {
+			excludes = append(excludes, s)
 		}
-
-		dateTo, ok := s.DateTo()
-		if !ok {
-			continue
-		}
-
-		excludes = append(excludes, DateRange{
-			From: dateFrom,
-			To:   dateTo,
-		})
 	}
 
-	date := t
+	date := localize(t, ss...)
 	delta := 1
 	if days < 0 {
 		delta = -1
 	}
 
+	skipped := 0 // guards against excludes that cover every date
 loop:
 	for days != 0 {
+		if skipped == businessHorizon {
+			return date, fmt.Errorf("unable to add %v days: %w", days, ErrNoAvailability)
+		}
+
 		date = date.AddDate(0, 0, delta)
-		dateStr := date.Format("2006-01-02")
 		for _, ex := range excludes {
-			if dateStr >= ex.From && dateStr <= ex.To {
+			if ex.Contains(date) {
+				skipped++
 				continue loop
 			}
 		}
 
+		skipped = 0
 		days -= delta
 	}
 
-	return date
+	return date, nil
+}
+
+// AddBusinessDays performs date arithmetic counting only dates the schedules
+// are open e.g. adding 1 business day to a Friday for a weekday schedule
+// returns the following Monday.  A date counts if TimeSlots for the date is
+// non-empty, including the after midnight portion of the previous date's
+// overnight hours.  Dates are evaluated in the location of the schedules, if
+// any
+func AddBusinessDays(t time.Time, days int, ss ...Schedule) (time.Time, error) {
+	date := localize(t, ss...)
+	delta := 1
+	if days < 0 {
+		delta = -1
+	}
+
+	r := newResolver(ss)
+	for closed := 0; days != 0; {
+		if closed > businessHorizon {
+			return time.Time{}, fmt.Errorf("unable to add %v business days: %w", days, ErrNoAvailability)
+		}
+
+		date = date.AddDate(0, 0, delta)
+		slots, err := r.timeSlots(date)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if len(slots) == 0 {
+			closed++
+			continue
+		}
+
+		closed = 0
+		days -= delta
+	}
+
+	return date, nil
+}
+
+// businessHorizon is the number of consecutive closed days business date and
+// duration arithmetic will search before giving up
+const businessHorizon = 366
+
+// AddBusinessDuration returns the time d of open time after t, counting only
+// time the schedules are open e.g. Friday 16:00 + 4h for a 09:00 - 17:00
+// weekday schedule is Monday 12:00.  A negative d counts backwards.  The time
+// returned is in the location of the schedules, if any
+func AddBusinessDuration(t time.Time, d time.Duration, ss ...Schedule) (time.Time, error) {
+	t = localize(t, ss...)
+	switch {
+	case d == 0:
+		return t, nil
+	case d < 0:
+		return subBusinessDuration(t, -d, ss...)
+	}
+
+	it := Schedules(ss).Iter(t, WithHorizon(businessHorizon))
+	for remaining := d; ; {
+		interval, ok := it.Next()
+		if !ok {
+			break
+		}
+		if v := interval.Duration(); remaining > v {
+			remaining -= v
+			continue
+		}
+		return interval.Start.Add(remaining), nil
+	}
+
+	if err := it.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, fmt.Errorf("unable to add business duration, %v: %w", d, ErrNoAvailability)
+}
+
+func subBusinessDuration(t time.Time, d time.Duration, ss ...Schedule) (time.Time, error) {
+	for remaining := d; remaining > 0; {
+		interval, err := PreviousWith(t, ss, WithHorizon(businessHorizon))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if v := interval.Duration(); remaining > v {
+			remaining -= v
+			t = interval.Start
+			continue
+		}
+		return interval.End.Add(-remaining), nil
+	}
+	return t, nil
+}
+
+// BusinessDurationBetween returns the amount of open time between a and b.  If b
+// is before a, the duration returned is negative
+func BusinessDurationBetween(a, b time.Time, ss ...Schedule) (time.Duration, error) {
+	if b.Before(a) {
+		d, err := BusinessDurationBetween(b, a, ss...)
+		return -d, err
+	}
+
+	intervals, err := Schedules(ss).Intervals(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	var d time.Duration
+	for _, interval := range intervals {
+		d += interval.Duration()
+	}
+	return d, nil
 }
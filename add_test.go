@@ -1,8 +1,11 @@
 package schedule
 
 import (
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/tj/assert"
 )
 
 func TestAddDate(t *testing.T) {
@@ -79,3 +82,203 @@ func TestAddDate(t *testing.T) {
 		})
 	}
 }
+
+func TestAddDate_ExcludeTimeRange(t *testing.T) {
+	date := time.Date(2020, time.July, 20, 0, 0, 0, 0, time.UTC)
+	got := AddDate(date, 1, ExcludeTimeRange("2020-07-21", "2020-07-21", 1200, 1400))
+	assert.Equal(t, "2020-07-21", got.Format(DateLayout))
+}
+
+func TestAddBusinessDuration(t *testing.T) {
+	var (
+		friday   = time.Date(2020, time.July, 24, 0, 0, 0, 0, time.UTC)
+		at       = func(days int, tm Time) time.Time { return tm.Align(friday.AddDate(0, 0, days)) }
+		weekdays = New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	)
+
+	testCases := map[string]struct {
+		Time      time.Time
+		Duration  time.Duration
+		Schedules Schedules
+		Want      time.Time
+	}{
+		"nop": {
+			Time:      at(0, 1600),
+			Schedules: Schedules{weekdays},
+			Want:      at(0, 1600),
+		},
+		"same day": {
+			Time:      at(0, 1000),
+			Duration:  2 * time.Hour,
+			Schedules: Schedules{weekdays},
+			Want:      at(0, 1200),
+		},
+		"closing time": {
+			Time:      at(0, 1600),
+			Duration:  time.Hour,
+			Schedules: Schedules{weekdays},
+			Want:      at(0, 1700),
+		},
+		"over weekend": {
+			Time:      at(0, 1600),
+			Duration:  4 * time.Hour,
+			Schedules: Schedules{weekdays},
+			Want:      at(3, 1200),
+		},
+		"before open": {
+			Time:      at(3, 600),
+			Duration:  8 * time.Hour,
+			Schedules: Schedules{weekdays},
+			Want:      at(3, 1700),
+		},
+		"holiday": {
+			Time:      at(0, 1600),
+			Duration:  4 * time.Hour,
+			Schedules: Schedules{weekdays, ExcludeDateRange("2020-07-27", "2020-07-27")},
+			Want:      at(4, 1200),
+		},
+		"override": {
+			Time:      at(0, 1600),
+			Duration:  4 * time.Hour,
+			Schedules: Schedules{weekdays, DateRange("2020-07-27", "2020-07-27", 1300, 1500)},
+			Want:      at(4, 1000),
+		},
+		"backwards": {
+			Time:      at(3, 1000),
+			Duration:  -4 * time.Hour,
+			Schedules: Schedules{weekdays},
+			Want:      at(0, 1400),
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := AddBusinessDuration(tc.Time, tc.Duration, tc.Schedules...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got)
+
+			d, err := BusinessDurationBetween(tc.Time, got, tc.Schedules...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Duration, d)
+		})
+	}
+}
+
+func TestAddBusinessDuration_Location(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	var (
+		ss   = Schedules{New(900, 1700)}.In(loc)
+		date = time.Date(2020, time.July, 24, 20, 0, 0, 0, time.UTC) // 16:00 EDT
+		want = time.Date(2020, time.July, 25, 11, 0, 0, 0, loc)
+	)
+
+	got, err := AddBusinessDuration(date, 3*time.Hour, ss...)
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestAddBusinessDuration_NoAvailability(t *testing.T) {
+	date := time.Date(2020, time.July, 24, 0, 0, 0, 0, time.UTC)
+	_, err := AddBusinessDuration(date, time.Hour, ExcludeDateRange("", ""))
+	assert.True(t, errors.Is(err, ErrNoAvailability))
+}
+
+func TestAddDate_Weekdays(t *testing.T) {
+	var (
+		friday  = time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC)
+		weekend = ExcludeDateRange("", "", time.Saturday, time.Sunday)
+	)
+
+	if got, want := AddDate(friday, 1, weekend).Format(DateLayout), "2020-07-27"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := AddDate(friday, -5, weekend).Format(DateLayout), "2020-07-17"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// excluding every date must still terminate
+	if got := AddDate(friday, 1, ExcludeDateRange("", "")); !got.After(friday) {
+		t.Fatalf("got %v; want after %v", got, friday)
+	}
+
+	t.Run("checked", func(t *testing.T) {
+		got, err := AddDateChecked(friday, -1, weekend)
+		assert.Nil(t, err)
+		assert.Equal(t, "2020-07-23", got.Format(DateLayout))
+
+		_, err = AddDateChecked(friday, 1, ExcludeDateRange("", ""))
+		assert.True(t, errors.Is(err, ErrNoAvailability))
+	})
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	var (
+		friday   = time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC)
+		weekdays = New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	)
+
+	testCases := map[string]struct {
+		Days      int
+		Schedules Schedules
+		Want      string
+	}{
+		"nop": {
+			Schedules: Schedules{weekdays},
+			Want:      "2020-07-24",
+		},
+		"next business day": {
+			Days:      1,
+			Schedules: Schedules{weekdays},
+			Want:      "2020-07-27",
+		},
+		"previous business day": {
+			Days:      -1,
+			Schedules: Schedules{weekdays},
+			Want:      "2020-07-23",
+		},
+		"holiday": {
+			Days:      1,
+			Schedules: Schedules{weekdays, ExcludeDateRange("2020-07-27", "2020-07-27")},
+			Want:      "2020-07-28",
+		},
+		"weekday exclude": {
+			Days:      2,
+			Schedules: Schedules{weekdays, ExcludeDateRange("", "", time.Monday)},
+			Want:      "2020-07-29",
+		},
+		"partial exclude still open": {
+			Days:      1,
+			Schedules: Schedules{weekdays, ExcludeTimeRange("2020-07-27", "2020-07-27", 1200, 1300)},
+			Want:      "2020-07-27",
+		},
+		"weekend override": {
+			Days:      1,
+			Schedules: Schedules{weekdays, DateRange("2020-07-25", "2020-07-25", 1000, 1400)},
+			Want:      "2020-07-25",
+		},
+		"overnight tail counts": {
+			Days:      1,
+			Schedules: Schedules{New(1800, 200, time.Friday, time.Monday)},
+			Want:      "2020-07-25",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := AddBusinessDays(friday, tc.Days, tc.Schedules...)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got := got.Format(DateLayout); got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+
+	t.Run("closed", func(t *testing.T) {
+		_, err := AddBusinessDays(friday, 1, ExcludeDateRange("", ""))
+		assert.True(t, errors.Is(err, ErrNoAvailability))
+	})
+}
@@ -1,21 +1,26 @@
 package schedule
 
 import (
-	"bytes"
 	"sort"
 	"time"
 )
 
 const DateLayout = "2006-01-02"
 
-// TimeSlot provides a range from a to b
+// TimeSlot provides a range from a to b.  A TimeSlot that runs past midnight
+// is overnight and has a To greater than EndOfDay e.g. 18:00 - 02:00 is
+// represented as {1800, 2600}
 type TimeSlot struct {
 	From Time // From time
 	To   Time // To time
 }
 
-// NewTimeSlot returns a new TimeSlot
+// NewTimeSlot returns a new TimeSlot.  If to is earlier than from, the
+// TimeSlot is assumed to run overnight into the following day
 func NewTimeSlot(from, to Time) TimeSlot {
+	if to < from {
+		to += EndOfDay
+	}
 	return TimeSlot{
 		From: from,
 		To:   to,
@@ -27,13 +32,21 @@ func (t TimeSlot) Contains(v TimeSlot) bool {
 	return t.From <= v.From && t.To >= v.To
 }
 
-// ContainsTime returns true if the Time is >= from and < to
+// ContainsTime returns true if the Time is >= from and < to.  For overnight
+// TimeSlots, times after midnight are matched against the overnight portion
 func (t TimeSlot) ContainsTime(tm time.Time) bool {
 	v := NewTime(tm.Hour(), tm.Minute())
+	if v >= t.From && v < t.To {
+		return true
+	}
+	v += EndOfDay
 	return v >= t.From && v < t.To
 }
 
-// Duration of this TimeSlot
+// Duration of this TimeSlot; overnight TimeSlots include the time after midnight.
+// Durations count each wall clock minute of the TimeSlot once; the hour
+// repeated when clocks fall back is not double counted while times skipped
+// when clocks spring forward do not count, see DurationOn
 func (t TimeSlot) Duration() time.Duration {
 	h := t.To.Hour() - t.From.Hour()
 	m := t.To.Minute() - t.From.Minute()
@@ -46,6 +59,34 @@ func (t TimeSlot) Duration() time.Duration {
 	return time.Duration(h*60+m) * time.Minute
 }
 
+// DurationOn returns the Duration of this TimeSlot on the date provided, in
+// the location of date e.g. 00:00 - 04:00 is 3h on a spring forward date and
+// 4h on a fall back date
+func (t TimeSlot) DurationOn(date time.Time) time.Duration {
+	d := t.To.Align(date).Sub(t.From.Align(date))
+	if v := t.Duration(); d > v {
+		return v // repeated wall clock times count once
+	}
+	return d
+}
+
+// Overnight returns true if the TimeSlot runs past midnight
+func (t TimeSlot) Overnight() bool {
+	return t.To > EndOfDay
+}
+
+// Tail returns the portion of an overnight TimeSlot that falls on the
+// following day, expressed relative to that day
+func (t TimeSlot) Tail() (TimeSlot, bool) {
+	if !t.Overnight() {
+		return TimeSlot{}, false
+	}
+	return TimeSlot{
+		From: max(t.From, EndOfDay) - EndOfDay,
+		To:   t.To - EndOfDay,
+	}, true
+}
+
 // Sub subtracts the TimeSlot provided the current TimeSlot
 // and returns the remaining TimeSlots
 func (t TimeSlot) Sub(v TimeSlot) []TimeSlot {
@@ -108,35 +149,14 @@ func (t TimeSlot) Union(v TimeSlot) TimeSlot {
 	}
 }
 
+// Hours returns the hours open on the date provided.  Hours are resolved
+// the same way as TimeSlots; ok is false if the date has no open hours
 func Hours(date time.Time, schedules ...Schedule) ([]TimeSlot, bool) {
-	sort.Slice(schedules, func(i, j int) bool {
-		return bytes.Compare(schedules[i], schedules[j]) < 0
-	})
-
-	// exclude takes precedence
-	for _, s := range schedules {
-		if s.IsExclude() && s.Contains(date) {
-			return nil, false // exclude trumps all other schedules
-		}
-	}
-
-	// check other elements
-	for _, s := range schedules {
-		if s.IsExclude() {
-			continue
-		}
-		if match := s.Contains(date); !match {
-			continue
-		}
-
-		block, err := s.TimeSlot()
-		if err != nil {
-			return nil, false
-		}
-
-		return []TimeSlot{block}, true
+	blocks, err := TimeSlots(date, schedules...)
+	if err != nil {
+		return nil, false
 	}
-	return nil, false
+	return blocks, len(blocks) > 0
 }
 
 func Availability(date time.Time, schedules []Schedule, reserved []TimeSlot) []TimeSlot {
@@ -412,3 +412,55 @@ func TestTimeSlot_Overlaps(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeSlot_Overnight(t *testing.T) {
+	var (
+		night    = NewTimeSlot(1800, 200)
+		midnight = NewTimeSlot(1800, 0)
+	)
+
+	assert.Equal(t, TimeSlot{From: 1800, To: 2600}, night)
+	assert.True(t, night.Overnight())
+	assert.Equal(t, 8*time.Hour, night.Duration())
+
+	tail, ok := night.Tail()
+	assert.True(t, ok)
+	assert.Equal(t, NewTimeSlot(Midnight, 200), tail)
+
+	assert.False(t, midnight.Overnight())
+	assert.Equal(t, 6*time.Hour, midnight.Duration())
+	_, ok = midnight.Tail()
+	assert.False(t, ok)
+
+	t.Run("contains", func(t *testing.T) {
+		date := time.Date(2020, time.July, 24, 0, 0, 0, 0, time.UTC)
+		assert.True(t, night.ContainsTime(NewTime(23, 0).Align(date)))
+		assert.True(t, night.ContainsTime(NewTime(1, 30).Align(date)))
+		assert.False(t, night.ContainsTime(NewTime(2, 0).Align(date)))
+		assert.False(t, night.ContainsTime(NewTime(12, 0).Align(date)))
+	})
+
+	t.Run("sub", func(t *testing.T) {
+		got := night.Sub(NewTimeSlot(2330, 30))
+		assert.Equal(t, []TimeSlot{NewTimeSlot(1800, 2330), {From: 2430, To: 2600}}, got)
+	})
+
+	t.Run("union", func(t *testing.T) {
+		got := Union(NewTimeSlot(2300, 100), night, NewTimeSlot(1200, 1800))
+		assert.Equal(t, []TimeSlot{NewTimeSlot(1200, 200)}, got)
+	})
+}
+
+func TestAvailability_Overnight(t *testing.T) {
+	var (
+		friday   = time.Date(2020, time.July, 24, 0, 0, 0, 0, time.UTC)
+		saturday = friday.AddDate(0, 0, 1)
+		bar      = []Schedule{New(1800, 200, time.Friday)}
+	)
+
+	got := Availability(friday, bar, []TimeSlot{NewTimeSlot(2330, 30)})
+	assert.Equal(t, []TimeSlot{NewTimeSlot(1800, 2330), {From: 2430, To: 2600}}, got)
+
+	got = Availability(saturday, bar, nil)
+	assert.Equal(t, []TimeSlot{NewTimeSlot(Midnight, 200)}, got)
+}
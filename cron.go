@@ -0,0 +1,417 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	cronMonths   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	cronWeekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	cronMacros   = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Cron is a cron expression along with the duration of each run e.g. a
+// maintenance window
+type Cron struct {
+	Expression string
+	Duration   time.Duration
+}
+
+// String implements fmt.Stringer
+func (c Cron) String() string {
+	return c.Expression + " for " + c.Duration.String()
+}
+
+// ParseCron converts a 5 field cron expression, minute hour day-of-month
+// month day-of-week, into Schedules open for duration d from each time the
+// expression fires e.g.
+//
+//	ParseCron("0 2 * * 0", 4*time.Hour)             // Sundays 02:00 - 06:00
+//	ParseCron("*/30 9-17 * * 1-5", 10*time.Minute)  // every half hour on weekdays
+//	ParseCron("CRON_TZ=Europe/London 0 22 1 * *", 3*time.Hour)
+//
+// Fields accept *, ranges, lists, steps and month and weekday names as well
+// as the @daily style macros.  A CRON_TZ or TZ prefix sets the location of
+// the Schedules.  When both day-of-month and day-of-week are restricted, as in
+// cron, a date matching either is selected.  Days of the month and months
+// become Recurrences e.g. DaysOfMonth(1).InMonths(time.January), so the
+// Schedules combine with others as regular hours
+func ParseCron(expr string, d time.Duration) (Schedules, error) {
+	ss, err := parseCron(expr, d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression, %q: %w", expr, err)
+	}
+	return ss, nil
+}
+
+// cronDays selects the days of a cron expression
+type cronDays struct {
+	weekdays   []time.Weekday
+	recurrence Recurrence
+}
+
+func parseCron(expr string, d time.Duration) (Schedules, error) {
+	var loc *time.Location
+	fields := strings.Fields(expr)
+	if len(fields) > 0 {
+		for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+			if !strings.HasPrefix(fields[0], prefix) {
+				continue
+			}
+			name := strings.TrimPrefix(fields[0], prefix)
+			v, ok := loadLocation(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown time zone, %v", name)
+			}
+			loc, fields = v, fields[1:]
+			break
+		}
+	}
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("unsupported macro, %v", fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %v", len(fields))
+	}
+	if d <= 0 || d > 24*time.Hour || d%time.Minute != 0 {
+		return nil, fmt.Errorf("invalid duration, %v", d)
+	}
+
+	minutes, err := cronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := cronField(fields[1], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+	days, err := cronField(fields[2], 1, 31, nil)
+	if err != nil {
+		return nil, err
+	}
+	months, err := cronField(fields[3], 1, 12, cronMonths)
+	if err != nil {
+		return nil, err
+	}
+	dows, err := cronField(fields[4], 0, 7, cronWeekdays)
+	if err != nil {
+		return nil, err
+	}
+
+	var weekdays []time.Weekday
+	for _, w := range mondayFirst {
+		for _, v := range dows {
+			if time.Weekday(v%7) == w {
+				weekdays = append(weekdays, w)
+				break
+			}
+		}
+	}
+
+	var sets []cronDays
+	switch dom, dow := len(days) < 31, len(weekdays) < len(mondayFirst); {
+	case dom && dow:
+		sets = []cronDays{{weekdays: weekdays}, {recurrence: DaysOfMonth(days...)}}
+	case dom:
+		sets = []cronDays{{recurrence: DaysOfMonth(days...)}}
+	case dow:
+		sets = []cronDays{{weekdays: weekdays}}
+	default:
+		sets = []cronDays{{}}
+	}
+	if len(months) < 12 {
+		var limit []time.Month
+		for _, m := range months {
+			limit = append(limit, time.Month(m))
+		}
+		for i := range sets {
+			sets[i].recurrence = sets[i].recurrence.InMonths(limit...)
+		}
+	}
+
+	everyDay := sets[0].weekdays == nil && sets[0].recurrence == ""
+
+	var slots []TimeSlot
+	for _, h := range hours {
+		for _, m := range minutes {
+			from := NewTime(h, m)
+			slot := NewTimeSlot(from, from.Add(d))
+			if d == 24*time.Hour {
+				slot.To = from + EndOfDay
+			}
+			slots = append(slots, slot)
+		}
+	}
+	slots = Union(slots...)
+	for _, slot := range slots {
+		if slot.To-slot.From < EndOfDay || slot == allDay {
+			continue
+		}
+		// runs last a day or more; only representable when every day is selected
+		if !everyDay {
+			return nil, fmt.Errorf("runs from %v last a day or more", slot.From)
+		}
+		slots = []TimeSlot{allDay}
+		break
+	}
+
+	var ss Schedules
+	for _, set := range sets {
+		for _, slot := range slots {
+			s := slotSchedule("", "", slot, set.weekdays...)
+			if set.recurrence != "" {
+				s = s.Recur(set.recurrence)
+			}
+			if loc != nil {
+				s = s.In(loc)
+			}
+			ss = append(ss, s)
+		}
+	}
+	return ss, nil
+}
+
+// cronField returns the sorted values selected by a cron field e.g. 1-5,
+// */15 or MON-FRI
+func cronField(field string, min, max int, names []string) ([]int, error) {
+	value := func(v string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(v, name) {
+				return min + i, nil
+			}
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("invalid value, %v", v)
+		}
+		return n, nil
+	}
+
+	selected := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step, %v", part)
+			}
+			part, step = part[:i], n
+		}
+
+		var from, to int
+		switch i := strings.Index(part, "-"); {
+		case part == "*" || part == "?":
+			from, to = min, max
+		case i >= 0:
+			var err error
+			if from, err = value(part[:i]); err != nil {
+				return nil, err
+			}
+			if to, err = value(part[i+1:]); err != nil {
+				return nil, err
+			}
+			if to < from {
+				return nil, fmt.Errorf("invalid range, %v", part)
+			}
+		default:
+			v, err := value(part)
+			if err != nil {
+				return nil, err
+			}
+			from, to = v, v
+			if step > 1 {
+				to = max
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			selected[v] = true
+		}
+	}
+
+	var values []int
+	for v := range selected {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// cronLastDay returns the last day of the month; annual February ranges
+// include the 29th
+func cronLastDay(month time.Month) int {
+	return time.Date(2000, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// FormatCron returns a cron expression for each Schedule; see ParseCron.
+// Excludes, date ranges other than whole annual months and recurrences other
+// than Months and positive DaysOfMonth, optionally limited to months, cannot
+// be represented.  Schedules combining
+// weekdays with days of the month are also rejected as cron selects dates
+// matching either
+func (s Schedules) FormatCron() ([]Cron, error) {
+	var crons []Cron
+	for _, item := range s {
+		c, err := cronFormat(item)
+		if err != nil {
+			return nil, fmt.Errorf("unable to format %v as cron: %w", item, err)
+		}
+		crons = append(crons, c)
+	}
+	return crons, nil
+}
+
+func cronFormat(s Schedule) (Cron, error) {
+	if s.IsExclude() {
+		return Cron{}, fmt.Errorf("excludes are not supported")
+	}
+
+	slot, err := s.TimeSlot()
+	if err != nil {
+		return Cron{}, err
+	}
+	if slot.From == slot.To {
+		return Cron{}, fmt.Errorf("empty time slot")
+	}
+
+	dom, month, dow := "*", "*", "*"
+	if r, ok := s.Recurrence(); ok {
+		rule, err := parseRecurrence(string(r))
+		if err != nil {
+			return Cron{}, err
+		}
+		if rule.kind != recurMonthly && rule.kind != recurMonths {
+			return Cron{}, fmt.Errorf("unsupported recurrence")
+		}
+		var days []int
+		for _, day := range rule.days {
+			if day.business || day.n < 0 {
+				return Cron{}, fmt.Errorf("unsupported recurrence")
+			}
+			days = append(days, day.n)
+		}
+		if len(days) > 0 {
+			dom = cronList(days)
+		}
+
+		var months []int
+		for _, m := range rule.months {
+			months = append(months, int(m))
+		}
+		if len(months) > 0 {
+			if s.HasDateRange() {
+				return Cron{}, fmt.Errorf("months with date ranges are not supported")
+			}
+			month = cronList(months)
+		}
+	}
+
+	if weekdays := s.Weekdays(); len(weekdays) > 0 {
+		if dom != "*" {
+			return Cron{}, fmt.Errorf("days of the month with weekdays are not supported")
+		}
+		var days []int
+		for _, w := range weekdays {
+			days = append(days, int(w))
+		}
+		dow = cronList(days)
+	}
+
+	if s.HasDateRange() {
+		from, _ := s.DateFrom()
+		to, _ := s.DateTo()
+		months, ok := cronMonthsBetween(from, to)
+		if !ok {
+			return Cron{}, fmt.Errorf("date ranges must select whole months")
+		}
+		month = cronList(months)
+	}
+
+	expr := fmt.Sprintf("%v %v %v %v %v", slot.From.Minute(), slot.From.Hour(), dom, month, dow)
+	if zone, ok := s.Zone(); ok {
+		expr = "CRON_TZ=" + zone + " " + expr
+	}
+
+	return Cron{
+		Expression: expr,
+		Duration:   slot.Duration(),
+	}, nil
+}
+
+// cronMonthsBetween returns the months of an annual date range from the first
+// through the last day of a month
+func cronMonthsBetween(from, to string) ([]int, bool) {
+	if !isAnnual(from) || !isAnnual(to) {
+		return nil, false
+	}
+
+	parse := func(date string) (time.Month, int, bool) {
+		if len(date) != len("--01-02") {
+			return 0, 0, false
+		}
+		m, err := strconv.Atoi(date[2:4])
+		if err != nil {
+			return 0, 0, false
+		}
+		d, err := strconv.Atoi(date[5:])
+		if err != nil {
+			return 0, 0, false
+		}
+		return time.Month(m), d, true
+	}
+	fm, fd, ok := parse(from)
+	if !ok || fd != 1 {
+		return nil, false
+	}
+	tm, td, ok := parse(to)
+	if !ok || (td != cronLastDay(tm) && !(tm == time.February && td == 28)) {
+		return nil, false
+	}
+
+	var months []int
+	for m := fm; ; m = m%12 + 1 {
+		months = append(months, int(m))
+		if m == tm {
+			return months, true
+		}
+	}
+}
+
+// cronList formats values, collapsing runs of three or more e.g. 1-5
+func cronList(values []int) string {
+	sort.Ints(values)
+
+	var parts []string
+	for i := 0; i < len(values); i++ {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, strconv.Itoa(values[i])+"-"+strconv.Itoa(values[j]))
+		default:
+			for _, v := range values[i : j+1] {
+				parts = append(parts, strconv.Itoa(v))
+			}
+		}
+		i = j
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,294 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	testCases := map[string]struct {
+		Input    string
+		Duration time.Duration
+		Want     []string
+	}{
+		"weekly": {
+			Input:    "0 2 * * 0",
+			Duration: 4 * time.Hour,
+			Want:     []string{"1:::0200:0600:Su:"},
+		},
+		"weekday names": {
+			Input:    "30 22 * * MON-FRI",
+			Duration: 3 * time.Hour,
+			Want:     []string{"1:::2230:0130:MoTuWeThFr:"},
+		},
+		"sunday as 7": {
+			Input:    "0 1 * * 6,7",
+			Duration: time.Hour,
+			Want:     []string{"1:::0100:0200:SaSu:"},
+		},
+		"daily": {
+			Input:    "@daily",
+			Duration: 30 * time.Minute,
+			Want:     []string{"1:::0000:0030::"},
+		},
+		"until midnight": {
+			Input:    "0 23 * * *",
+			Duration: time.Hour,
+			Want:     []string{"1:::2300:2400::"},
+		},
+		"hours": {
+			Input:    "0 9,17 * * 1-5",
+			Duration: 30 * time.Minute,
+			Want:     []string{"1:::0900:0930:MoTuWeThFr:", "1:::1700:1730:MoTuWeThFr:"},
+		},
+		"steps merge": {
+			Input:    "*/15 9-10 * * *",
+			Duration: 15 * time.Minute,
+			Want:     []string{"1:::0900:1100::"},
+		},
+		"every minute": {
+			Input:    "* * * * *",
+			Duration: time.Minute,
+			Want:     []string{"1:::0000:2400::"},
+		},
+		"overlapping a day": {
+			Input:    "0 */2 * * *",
+			Duration: 3 * time.Hour,
+			Want:     []string{"1:::0000:2400::"},
+		},
+		"days of month": {
+			Input:    "0 3 1,15 * *",
+			Duration: 2 * time.Hour,
+			Want:     []string{"4:::0300:0500::::monthly=1/15"},
+		},
+		"days of month or weekdays": {
+			Input:    "0 3 1 * SUN",
+			Duration: 2 * time.Hour,
+			Want:     []string{"1:::0300:0500:Su:", "4:::0300:0500::::monthly=1"},
+		},
+		"months": {
+			Input:    "0 4 * JAN,JUL * ",
+			Duration: time.Hour,
+			Want:     []string{"4:::0400:0500::::months=1/7"},
+		},
+		"month range wraps": {
+			Input:    "0 4 * 1-2,11-12 6",
+			Duration: time.Hour,
+			Want:     []string{"4:::0400:0500:Sa:::months=1/2/11/12"},
+		},
+		"yearly": {
+			Input:    "@yearly",
+			Duration: 24 * time.Hour,
+			Want:     []string{"4:::0000:2400::::monthly=1;months=1"},
+		},
+		"zone": {
+			Input:    "CRON_TZ=America/New_York 0 22 * * 5",
+			Duration: 8 * time.Hour,
+			Want:     []string{"2:::2200:0600:Fr::America/New_York"},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			ss, err := ParseCron(tc.Input, tc.Duration)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, ss.StringSlice())
+
+			for _, s := range ss {
+				_, err := Parse(s.String())
+				assert.Nil(t, err)
+			}
+		})
+	}
+
+	t.Run("months combine with regular hours", func(t *testing.T) {
+		ss, err := ParseCron("0 6 * JAN *", 2*time.Hour)
+		assert.Nil(t, err)
+		ss = append(ss, New(900, 1700))
+
+		got, err := ss.TimeSlots(time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(600, 800), NewTimeSlot(900, 1700)}, got)
+	})
+
+	t.Run("blocked time", func(t *testing.T) {
+		hours := Schedules{New(800, 1800, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)}
+		maintenance, err := ParseCron("0 12 * * 1-5", 90*time.Minute)
+		assert.Nil(t, err)
+
+		date := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+		blocked, err := maintenance.TimeSlots(date)
+		assert.Nil(t, err)
+
+		got := Availability(date, hours, blocked)
+		assert.Equal(t, []TimeSlot{{From: 800, To: 1200}, {From: 1330, To: 1800}}, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := map[string]struct {
+			Input    string
+			Duration time.Duration
+			Want     string
+		}{
+			"fields": {
+				Input:    "0 2 * *",
+				Duration: time.Hour,
+				Want:     `invalid cron expression, "0 2 * *": expected 5 fields, got 4`,
+			},
+			"value": {
+				Input:    "0 24 * * *",
+				Duration: time.Hour,
+				Want:     `invalid cron expression, "0 24 * * *": invalid value, 24`,
+			},
+			"name": {
+				Input:    "0 2 * * MONDAY",
+				Duration: time.Hour,
+				Want:     `invalid cron expression, "0 2 * * MONDAY": invalid value, MONDAY`,
+			},
+			"step": {
+				Input:    "*/0 2 * * *",
+				Duration: time.Hour,
+				Want:     `invalid cron expression, "*/0 2 * * *": invalid step, */0`,
+			},
+			"range": {
+				Input:    "0 2 * * 5-1",
+				Duration: time.Hour,
+				Want:     `invalid cron expression, "0 2 * * 5-1": invalid range, 5-1`,
+			},
+			"macro": {
+				Input:    "@reboot",
+				Duration: time.Hour,
+				Want:     `invalid cron expression, "@reboot": unsupported macro, @reboot`,
+			},
+			"zone": {
+				Input:    "CRON_TZ=Mars/Olympus 0 2 * * *",
+				Duration: time.Hour,
+				Want:     `invalid cron expression, "CRON_TZ=Mars/Olympus 0 2 * * *": unknown time zone, Mars/Olympus`,
+			},
+			"duration": {
+				Input:    "0 2 * * *",
+				Duration: 90 * time.Second,
+				Want:     `invalid cron expression, "0 2 * * *": invalid duration, 1m30s`,
+			},
+			"longer than a day": {
+				Input:    "0 */2 * * 1",
+				Duration: 3 * time.Hour,
+				Want:     `invalid cron expression, "0 */2 * * 1": runs from 00:00 last a day or more`,
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				_, err := ParseCron(tc.Input, tc.Duration)
+				assert.EqualError(t, err, tc.Want)
+			})
+		}
+	})
+}
+
+func TestSchedules_FormatCron(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		Want      []Cron
+	}{
+		"weekdays": {
+			Schedules: Schedules{New(2230, 130, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)},
+			Want:      []Cron{{Expression: "30 22 * * 1-5", Duration: 3 * time.Hour}},
+		},
+		"every day": {
+			Schedules: Schedules{New(Midnight, EndOfDay)},
+			Want:      []Cron{{Expression: "0 0 * * *", Duration: 24 * time.Hour}},
+		},
+		"split weekdays": {
+			Schedules: Schedules{New(900, 930, time.Sunday, time.Wednesday, time.Saturday)},
+			Want:      []Cron{{Expression: "0 9 * * 0,3,6", Duration: 30 * time.Minute}},
+		},
+		"days of month": {
+			Schedules: Schedules{New(300, 500).Recur(DaysOfMonth(15, 1))},
+			Want:      []Cron{{Expression: "0 3 1,15 * *", Duration: 2 * time.Hour}},
+		},
+		"months": {
+			Schedules: Schedules{DateRange(Annual(time.November, 1), Annual(time.February, 29), 400, 500, time.Saturday)},
+			Want:      []Cron{{Expression: "0 4 * 1,2,11,12 6", Duration: time.Hour}},
+		},
+		"months recurrence": {
+			Schedules: Schedules{New(400, 500, time.Saturday).Recur(Months(time.January, time.July))},
+			Want:      []Cron{{Expression: "0 4 * 1,7 6", Duration: time.Hour}},
+		},
+		"days of months": {
+			Schedules: Schedules{New(400, 500).Recur(DaysOfMonth(1).InMonths(time.March, time.April, time.May))},
+			Want:      []Cron{{Expression: "0 4 1 3-5 *", Duration: time.Hour}},
+		},
+		"zone": {
+			Schedules: Schedules{New(2200, 600, time.Friday).In(loc)},
+			Want:      []Cron{{Expression: "CRON_TZ=America/New_York 0 22 * * 5", Duration: 8 * time.Hour}},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := tc.Schedules.FormatCron()
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got)
+
+			// formatted expressions parse back to the same hours
+			var ss Schedules
+			for _, c := range got {
+				v, err := ParseCron(c.Expression, c.Duration)
+				assert.Nil(t, err)
+				ss = append(ss, v...)
+			}
+			for date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC); date.Year() == 2020; date = date.AddDate(0, 0, 1) {
+				want, err := tc.Schedules.TimeSlots(date)
+				assert.Nil(t, err)
+				actual, err := ss.TimeSlots(date)
+				assert.Nil(t, err)
+				assert.Equal(t, want, actual, date.Format(DateLayout))
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Want     string
+		}{
+			"exclude": {
+				Schedule: ExcludeDateRange("2020-12-25", "2020-12-25"),
+				Want:     "unable to format 1:2020-12-25:2020-12-25:0000:0000::exclude as cron: excludes are not supported",
+			},
+			"date range": {
+				Schedule: DateRange("2020-12-24", "2020-12-24", 1000, 1400),
+				Want:     "unable to format 1:2020-12-24:2020-12-24:1000:1400:: as cron: date ranges must select whole months",
+			},
+			"partial month": {
+				Schedule: DateRange(Annual(time.December, 1), Annual(time.December, 24), 1000, 1400),
+				Want:     "unable to format 3:--12-01:--12-24:1000:1400:: as cron: date ranges must select whole months",
+			},
+			"recurrence": {
+				Schedule: New(900, 1700).Recur(EveryWeeks(2, "2020-01-04")),
+				Want:     "unable to format 4:::0900:1700::::every=2w/2020-01-04 as cron: unsupported recurrence",
+			},
+			"last day of month": {
+				Schedule: New(900, 1700).Recur(DaysOfMonth(-1)),
+				Want:     "unable to format 4:::0900:1700::::monthly=-1 as cron: unsupported recurrence",
+			},
+			"weekdays and days of month": {
+				Schedule: New(900, 1700, time.Monday).Recur(DaysOfMonth(1)),
+				Want:     "unable to format 4:::0900:1700:Mo:::monthly=1 as cron: days of the month with weekdays are not supported",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				_, err := Schedules{tc.Schedule}.FormatCron()
+				assert.EqualError(t, err, tc.Want)
+			})
+		}
+	})
+}
@@ -0,0 +1,168 @@
+// Package holidays generates schedule exclusions for public holidays.  All
+// dates are computed offline, including Easter via the Gregorian computus, so
+// no external data source is required.
+package holidays
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/savaki/schedule"
+)
+
+// Region identifies a holiday calendar
+type Region string
+
+const (
+	// US federal holidays
+	US Region = "US"
+	// UK bank holidays for England and Wales
+	UK Region = "UK"
+	// Canada federal statutory holidays
+	Canada Region = "CA"
+)
+
+// Holiday describes a single public holiday
+type Holiday struct {
+	Name     string    // Name of the holiday
+	Date     time.Time // Date the holiday falls on
+	Observed time.Time // Observed date; differs from Date when shifted off a weekend
+}
+
+// observe shifts holidays that fall on a weekend
+type observe func(holidays []Holiday) []Holiday
+
+type calendar struct {
+	holidays func(year int) []Holiday
+	observe  observe
+}
+
+var calendars = map[Region]calendar{
+	US:     {holidays: us, observe: nearestWeekday},
+	UK:     {holidays: uk, observe: nextFreeWeekday},
+	Canada: {holidays: canada, observe: nextFreeWeekday},
+}
+
+// Holidays returns the holidays for the region from fromYear through toYear
+// inclusive, sorted by observed date
+func Holidays(region Region, fromYear, toYear int) ([]Holiday, error) {
+	c, ok := calendars[region]
+	if !ok {
+		return nil, fmt.Errorf("unknown holiday region, %v", region)
+	}
+
+	var holidays []Holiday
+	for year := fromYear; year <= toYear; year++ {
+		holidays = append(holidays, c.observe(c.holidays(year))...)
+	}
+
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Observed.Before(holidays[j].Observed)
+	})
+
+	return holidays, nil
+}
+
+// Schedules returns exclusions for the observed date of each holiday in the
+// region from fromYear through toYear inclusive
+func Schedules(region Region, fromYear, toYear int) (schedule.Schedules, error) {
+	holidays, err := Holidays(region, fromYear, toYear)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss schedule.Schedules
+	for _, h := range holidays {
+		date := h.Observed.Format(schedule.DateLayout)
+		ss = append(ss, schedule.ExcludeDateRange(date, date))
+	}
+	return ss, nil
+}
+
+// Easter returns the date of Western Easter Sunday using the anonymous
+// Gregorian algorithm
+func Easter(year int) time.Time {
+	var (
+		a = year % 19
+		b = year / 100
+		c = year % 100
+		d = b / 4
+		e = b % 4
+		f = (b + 8) / 25
+		g = (b - f + 1) / 3
+		h = (19*a + b - d - g + 15) % 30
+		i = c / 4
+		k = c % 4
+		l = (32 + 2*e + 2*i - h - k) % 7
+		m = (a + 11*h + 22*l) / 451
+	)
+
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday returns the nth weekday of the month; a negative n counts back
+// from the end of the month
+func nthWeekday(year int, month time.Month, n int, weekday time.Weekday) time.Time {
+	if n < 0 {
+		last := date(year, month+1, 0)
+		offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, -offset+(n+1)*7)
+	}
+
+	first := date(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+(n-1)*7)
+}
+
+func holiday(name string, t time.Time) Holiday {
+	return Holiday{Name: name, Date: t, Observed: t}
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// nearestWeekday observes Saturday holidays on the preceding Friday and Sunday
+// holidays on the following Monday
+func nearestWeekday(holidays []Holiday) []Holiday {
+	for i, h := range holidays {
+		switch h.Date.Weekday() {
+		case time.Saturday:
+			holidays[i].Observed = h.Date.AddDate(0, 0, -1)
+		case time.Sunday:
+			holidays[i].Observed = h.Date.AddDate(0, 0, 1)
+		}
+	}
+	return holidays
+}
+
+// nextFreeWeekday observes weekend holidays on the next weekday that is not
+// already a holiday e.g. Christmas on a Saturday is observed Monday and Boxing
+// Day on the Sunday is observed Tuesday
+func nextFreeWeekday(holidays []Holiday) []Holiday {
+	taken := map[time.Time]bool{}
+	for _, h := range holidays {
+		taken[h.Date] = true
+	}
+
+	for i, h := range holidays {
+		if !isWeekend(h.Date) {
+			continue
+		}
+
+		observed := h.Date
+		for isWeekend(observed) || taken[observed] {
+			observed = observed.AddDate(0, 0, 1)
+		}
+		taken[observed] = true
+		holidays[i].Observed = observed
+	}
+	return holidays
+}
@@ -0,0 +1,144 @@
+package holidays
+
+import (
+	"testing"
+	"time"
+
+	"github.com/savaki/schedule"
+	"github.com/tj/assert"
+)
+
+func TestEaster(t *testing.T) {
+	testCases := map[int]string{
+		1961: "1961-04-02",
+		2019: "2019-04-21",
+		2020: "2020-04-12",
+		2021: "2021-04-04",
+		2024: "2024-03-31",
+		2038: "2038-04-25",
+		2285: "2285-03-22",
+	}
+
+	for year, want := range testCases {
+		assert.Equal(t, want, Easter(year).Format(schedule.DateLayout), "%v", year)
+	}
+}
+
+func TestHolidays(t *testing.T) {
+	testCases := map[string]struct {
+		Region Region
+		Year   int
+		Want   []string
+	}{
+		"us 2020": {
+			Region: US,
+			Year:   2020,
+			Want: []string{
+				"2020-01-01", "2020-01-20", "2020-02-17", "2020-05-25", "2020-07-03",
+				"2020-09-07", "2020-10-12", "2020-11-11", "2020-11-26", "2020-12-25",
+			},
+		},
+		"us 2021": {
+			Region: US,
+			Year:   2021,
+			Want: []string{
+				"2021-01-01", "2021-01-18", "2021-02-15", "2021-05-31", "2021-06-18",
+				"2021-07-05", "2021-09-06", "2021-10-11", "2021-11-11", "2021-11-25",
+				"2021-12-24",
+			},
+		},
+		"us 2022 - new year observed prior year": {
+			Region: US,
+			Year:   2022,
+			Want: []string{
+				"2021-12-31", "2022-01-17", "2022-02-21", "2022-05-30", "2022-06-20",
+				"2022-07-04", "2022-09-05", "2022-10-10", "2022-11-11", "2022-11-24",
+				"2022-12-26",
+			},
+		},
+		"uk 2020": {
+			Region: UK,
+			Year:   2020,
+			Want: []string{
+				"2020-01-01", "2020-04-10", "2020-04-13", "2020-05-08", "2020-05-25",
+				"2020-08-31", "2020-12-25", "2020-12-28",
+			},
+		},
+		"uk 2021 - christmas on saturday": {
+			Region: UK,
+			Year:   2021,
+			Want: []string{
+				"2021-01-01", "2021-04-02", "2021-04-05", "2021-05-03", "2021-05-31",
+				"2021-08-30", "2021-12-27", "2021-12-28",
+			},
+		},
+		"uk 2022 - christmas on sunday": {
+			Region: UK,
+			Year:   2022,
+			Want: []string{
+				"2022-01-03", "2022-04-15", "2022-04-18", "2022-05-02", "2022-06-02",
+				"2022-06-03", "2022-08-29", "2022-09-19", "2022-12-26", "2022-12-27",
+			},
+		},
+		"canada 2023": {
+			Region: Canada,
+			Year:   2023,
+			Want: []string{
+				"2023-01-02", "2023-04-07", "2023-05-22", "2023-07-03", "2023-09-04",
+				"2023-10-02", "2023-10-09", "2023-11-13", "2023-12-25", "2023-12-26",
+			},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			holidays, err := Holidays(tc.Region, tc.Year, tc.Year)
+			assert.Nil(t, err)
+
+			var got []string
+			for _, h := range holidays {
+				got = append(got, h.Observed.Format(schedule.DateLayout))
+			}
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestHolidays_Observed(t *testing.T) {
+	holidays, err := Holidays(US, 2020, 2020)
+	assert.Nil(t, err)
+
+	for _, h := range holidays {
+		if h.Name == "Independence Day" {
+			assert.Equal(t, "2020-07-04", h.Date.Format(schedule.DateLayout))
+			assert.Equal(t, "2020-07-03", h.Observed.Format(schedule.DateLayout))
+			return
+		}
+	}
+	t.Fatalf("Independence Day not found")
+}
+
+func TestHolidays_UnknownRegion(t *testing.T) {
+	_, err := Holidays("XX", 2020, 2020)
+	assert.EqualError(t, err, "unknown holiday region, XX")
+}
+
+func TestSchedules(t *testing.T) {
+	ss, err := Schedules(US, 2020, 2021)
+	assert.Nil(t, err)
+	assert.Len(t, ss, 21)
+	assert.Equal(t, schedule.ExcludeDateRange("2020-01-01", "2020-01-01"), ss[0])
+
+	var (
+		weekdays = schedule.New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		thursday = time.Date(2020, time.July, 2, 12, 0, 0, 0, time.UTC)
+		all      = append(schedule.Schedules{weekdays}, ss...)
+	)
+
+	got := schedule.AddDate(thursday, 1, ss...)
+	assert.Equal(t, "2020-07-04", got.Format(schedule.DateLayout))
+
+	got, err = schedule.AddBusinessDays(thursday, 1, all...)
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-07-06", got.Format(schedule.DateLayout))
+}
@@ -0,0 +1,107 @@
+package holidays
+
+import (
+	"time"
+)
+
+// us returns US federal holidays per 5 U.S.C. 6103
+func us(year int) []Holiday {
+	holidays := []Holiday{
+		holiday("New Year's Day", date(year, time.January, 1)),
+		holiday("Birthday of Martin Luther King, Jr.", nthWeekday(year, time.January, 3, time.Monday)),
+		holiday("Washington's Birthday", nthWeekday(year, time.February, 3, time.Monday)),
+		holiday("Memorial Day", nthWeekday(year, time.May, -1, time.Monday)),
+		holiday("Independence Day", date(year, time.July, 4)),
+		holiday("Labor Day", nthWeekday(year, time.September, 1, time.Monday)),
+		holiday("Columbus Day", nthWeekday(year, time.October, 2, time.Monday)),
+		holiday("Veterans Day", date(year, time.November, 11)),
+		holiday("Thanksgiving Day", nthWeekday(year, time.November, 4, time.Thursday)),
+		holiday("Christmas Day", date(year, time.December, 25)),
+	}
+	if year >= 2021 {
+		holidays = append(holidays, holiday("Juneteenth National Independence Day", date(year, time.June, 19)))
+	}
+	return holidays
+}
+
+// ukSpecial holds one off bank holidays and moved bank holidays in England
+// and Wales
+var ukSpecial = map[int]struct {
+	moved map[string]time.Time
+	extra []Holiday
+}{
+	2011: {
+		extra: []Holiday{holiday("Royal Wedding", date(2011, time.April, 29))},
+	},
+	2012: {
+		moved: map[string]time.Time{"Spring Bank Holiday": date(2012, time.June, 4)},
+		extra: []Holiday{holiday("Queen's Diamond Jubilee", date(2012, time.June, 5))},
+	},
+	2020: {
+		moved: map[string]time.Time{"Early May Bank Holiday": date(2020, time.May, 8)},
+	},
+	2022: {
+		moved: map[string]time.Time{"Spring Bank Holiday": date(2022, time.June, 2)},
+		extra: []Holiday{
+			holiday("Queen's Platinum Jubilee", date(2022, time.June, 3)),
+			holiday("State Funeral of Queen Elizabeth II", date(2022, time.September, 19)),
+		},
+	},
+	2023: {
+		extra: []Holiday{holiday("Coronation of King Charles III", date(2023, time.May, 8))},
+	},
+}
+
+// uk returns bank holidays for England and Wales
+func uk(year int) []Holiday {
+	easter := Easter(year)
+	holidays := []Holiday{
+		holiday("New Year's Day", date(year, time.January, 1)),
+		holiday("Good Friday", easter.AddDate(0, 0, -2)),
+		holiday("Easter Monday", easter.AddDate(0, 0, 1)),
+		holiday("Early May Bank Holiday", nthWeekday(year, time.May, 1, time.Monday)),
+		holiday("Spring Bank Holiday", nthWeekday(year, time.May, -1, time.Monday)),
+		holiday("Summer Bank Holiday", nthWeekday(year, time.August, -1, time.Monday)),
+		holiday("Christmas Day", date(year, time.December, 25)),
+		holiday("Boxing Day", date(year, time.December, 26)),
+	}
+
+	if special, ok := ukSpecial[year]; ok {
+		for i, h := range holidays {
+			if t, ok := special.moved[h.Name]; ok {
+				holidays[i] = holiday(h.Name, t)
+			}
+		}
+		holidays = append(holidays, special.extra...)
+	}
+
+	return holidays
+}
+
+// canada returns federal statutory holidays in Canada
+func canada(year int) []Holiday {
+	holidays := []Holiday{
+		holiday("New Year's Day", date(year, time.January, 1)),
+		holiday("Good Friday", Easter(year).AddDate(0, 0, -2)),
+		holiday("Victoria Day", victoriaDay(year)),
+		holiday("Canada Day", date(year, time.July, 1)),
+		holiday("Labour Day", nthWeekday(year, time.September, 1, time.Monday)),
+		holiday("Thanksgiving", nthWeekday(year, time.October, 2, time.Monday)),
+		holiday("Remembrance Day", date(year, time.November, 11)),
+		holiday("Christmas Day", date(year, time.December, 25)),
+		holiday("Boxing Day", date(year, time.December, 26)),
+	}
+	if year >= 2021 {
+		holidays = append(holidays, holiday("National Day for Truth and Reconciliation", date(year, time.September, 30)))
+	}
+	return holidays
+}
+
+// victoriaDay returns the Monday preceding May 25
+func victoriaDay(year int) time.Time {
+	t := date(year, time.May, 24)
+	for t.Weekday() != time.Monday {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
@@ -0,0 +1,1201 @@
+package schedule
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icalDate     = "20060102"
+	icalDateTime = "20060102T150405"
+	icalProduct  = "-//savaki//schedule//EN"
+	icalLine     = 75      // maximum octets per content line
+	icalSearch   = 8 * 366 // maximum number of dates enumerated for a rule
+	icalAllDay   = "X-SCHEDULE-ALL-DAY"
+
+	// maxDate closes the date range of open ended rules
+	maxDate = "9999-12-31"
+)
+
+// ICalendarError lists the rules that could not be represented when
+// converting to or from iCalendar.  Everything else is still converted
+type ICalendarError struct {
+	Unsupported []string // Unsupported rules, each with the reason
+}
+
+// Error implements error
+func (e *ICalendarError) Error() string {
+	return fmt.Sprintf("unsupported iCalendar rules: %v", strings.Join(e.Unsupported, "; "))
+}
+
+// MarshalICalendar encodes the Schedules as a VCALENDAR with one VEVENT per
+// include Schedule e.g. weekly hours become FREQ=WEEKLY;BYDAY=MO,TU and
+// excluded dates become EXDATEs.  Each event starts on its first date on or
+// after start.  Zones are referenced by TZID and defined by a VTIMEZONE with
+// the daylight saving rules in effect in the year of start.  EXDATEs of
+// excludes covering the entire day are marked X-SCHEDULE-ALL-DAY=TRUE so
+// ParseICalendar restores them as ExcludeDateRange.
+//
+// Schedules that cannot be represented, e.g. excludes without a date range,
+// are reported by an *ICalendarError along with the remaining events
+func (s Schedules) MarshalICalendar(start time.Time) ([]byte, error) {
+	var (
+		includes    []Schedule
+		excludes    []Schedule
+		unsupported []string
+		reported    = map[string]bool{}
+		report      = func(s Schedule, err error) {
+			if v := fmt.Sprintf("%v: %v", s, err); !reported[v] {
+				reported[v] = true
+				unsupported = append(unsupported, v)
+			}
+		}
+	)
+
+	for _, item := range s {
+		switch {
+		case !item.IsExclude():
+			includes = append(includes, item)
+		case !icalBounded(item):
+			report(item, fmt.Errorf("exclude requires a date range"))
+		default:
+			excludes = append(excludes, item)
+		}
+	}
+
+	var (
+		events []string
+		zones  = map[string]bool{}
+		lines  = []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:" + icalProduct}
+	)
+	for _, include := range includes {
+		event, err := encodeEvent(include, includes, excludes, start, report)
+		if err != nil {
+			report(include, err)
+			continue
+		}
+		events = append(events, event...)
+
+		if loc, ok := include.Location(); ok && !zones[loc.String()] {
+			zones[loc.String()] = true
+			lines = append(lines, icalTimezone(loc, start.In(loc).Year())...)
+		}
+	}
+	lines = append(lines, events...)
+	lines = append(lines, "END:VCALENDAR")
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		icalFold(&buf, line)
+	}
+
+	if len(unsupported) > 0 {
+		return buf.Bytes(), &ICalendarError{Unsupported: unsupported}
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeEvent returns the content lines of the VEVENT for an include.  Excludes
+// and date range overrides that cannot be applied to the event are reported
+func encodeEvent(s Schedule, includes, excludes []Schedule, start time.Time, report func(Schedule, error)) ([]string, error) {
+	slot, err := s.TimeSlot()
+	if err != nil {
+		return nil, err
+	}
+
+	rule, from, to, err := icalRule(s)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, zoned := s.Location()
+	if !zoned {
+		loc = time.UTC // floating time
+	}
+	if v := localize(start, s).Format(DateLayout); v > from {
+		from = v
+	}
+
+	first, ok := icalFirst(s, from, to, loc)
+	if !ok {
+		return nil, fmt.Errorf("no dates on or after %v", from)
+	}
+
+	var (
+		exdates   []string
+		closed    []string // exdates of excludes covering the entire day
+		firstDate = first.Format(DateLayout)
+		exdate    = func(within Schedule, dates *[]string) func(date time.Time) {
+			return func(date time.Time) {
+				if within.Contains(date) && s.Contains(date) {
+					*dates = append(*dates, icalWall(date, slot.From).Format(icalDateTime))
+				}
+			}
+		}
+	)
+
+	for _, ex := range excludes {
+		window, err := ex.TimeSlot()
+		if err != nil {
+			report(ex, err)
+			continue
+		}
+		switch {
+		case ex.ExcludesAllDay(), window.Contains(slot):
+		case window.From < slot.To && slot.From < window.To:
+			report(ex, fmt.Errorf("exclude covers part of %v - %v", slot.From, slot.To))
+			continue
+		default:
+			continue
+		}
+
+		dates := &exdates
+		if ex.ExcludesAllDay() {
+			dates = &closed
+		}
+
+		exFrom, _ := ex.DateFrom()
+		exTo, _ := ex.DateTo()
+		if !icalDates(icalMax(exFrom, firstDate), exTo, loc, exdate(ex, dates)) {
+			report(ex, fmt.Errorf("date range too long to enumerate"))
+		}
+	}
+
+	// date range overrides replace regular hours rather than adding to them
+	if s.Priority() == PriorityRegular {
+		for _, o := range includes {
+			if o.Priority() != PriorityOverride {
+				continue
+			}
+			oFrom, _ := o.DateFrom()
+			oTo, _ := o.DateTo()
+			if !icalBounded(o) || !icalDates(icalMax(oFrom, firstDate), oTo, loc, exdate(o, &exdates)) {
+				report(o, fmt.Errorf("override of regular hours requires a bounded date range"))
+			}
+		}
+	}
+
+	var (
+		tzid = ""
+		hash = fnv.New64a()
+	)
+	if zoned {
+		tzid = ";TZID=" + loc.String()
+	}
+	hash.Write(s)
+
+	lines := []string{
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:%x@schedule", hash.Sum64()),
+		"DTSTAMP:" + start.UTC().Format(icalDateTime) + "Z",
+		"DTSTART" + tzid + ":" + icalWall(first, slot.From).Format(icalDateTime),
+		"DTEND" + tzid + ":" + icalWall(first, slot.To).Format(icalDateTime),
+	}
+	if firstDate != to {
+		if to != "" {
+			rule += ";UNTIL=" + icalUntil(to, zoned, loc)
+		}
+		lines = append(lines, "RRULE:"+rule)
+	}
+	if len(exdates) > 0 {
+		sort.Strings(exdates)
+		lines = append(lines, "EXDATE"+tzid+":"+strings.Join(icalUnique(exdates), ","))
+	}
+	if len(closed) > 0 {
+		sort.Strings(closed)
+		lines = append(lines, "EXDATE"+tzid+";"+icalAllDay+"=TRUE:"+strings.Join(icalUnique(closed), ","))
+	}
+	lines = append(lines, "END:VEVENT")
+
+	return lines, nil
+}
+
+// icalRule returns the RRULE, without UNTIL, matching the dates of the
+// Schedule along with the dates, if any, the rule is bounded by
+func icalRule(s Schedule) (rule, from, to string, err error) {
+	var (
+		dateFrom, _ = s.DateFrom()
+		dateTo, _   = s.DateTo()
+		byday       = icalWeekdays(s.Weekdays()...)
+		parts       []string
+	)
+
+	from, to = dateFrom, dateTo
+	if to == maxDate {
+		to = ""
+	}
+
+	r, recurs := s.Recurrence()
+	switch {
+	case isAnnual(dateFrom) && recurs:
+		return "", "", "", fmt.Errorf("annual dates with a recurrence")
+
+	case isAnnual(dateFrom) && dateFrom != dateTo:
+		return "", "", "", fmt.Errorf("annual date ranges")
+
+	case isAnnual(dateFrom):
+		month, _ := strconv.Atoi(dateFrom[2:4])
+		day, _ := strconv.Atoi(dateFrom[5:7])
+		parts = append(parts, "FREQ=YEARLY", fmt.Sprintf("BYMONTH=%v", month), fmt.Sprintf("BYMONTHDAY=%v", day))
+		from, to = "", ""
+
+	case recurs:
+		rec, err := parseRecurrence(string(r))
+		if err != nil {
+			return "", "", "", err
+		}
+
+		switch rec.kind {
+		case recurNth:
+			if rec.offset != 0 {
+				return "", "", "", fmt.Errorf("nth weekday offsets")
+			}
+			if byday != "" {
+				return "", "", "", fmt.Errorf("nth weekday restricted to weekdays")
+			}
+			parts = append(parts, "FREQ=YEARLY", fmt.Sprintf("BYMONTH=%v", int(rec.month)), fmt.Sprintf("BYDAY=%v%v", rec.n, icalWeekdays(rec.weekday)))
+			byday = ""
+
+		case recurEvery:
+			from = icalMax(from, rec.anchor.Format(DateLayout))
+			if rec.unit == 'd' {
+				parts = append(parts, "FREQ=DAILY", fmt.Sprintf("INTERVAL=%v", rec.n))
+				break
+			}
+			if byday == "" {
+				byday = icalWeekdays(rec.anchor.Weekday())
+			}
+			parts = append(parts, "FREQ=WEEKLY", fmt.Sprintf("INTERVAL=%v", rec.n), "BYDAY="+byday, "WKST="+icalWeekdays(rec.anchor.Weekday()))
+			byday = ""
+
+		case recurMonthly:
+			var days, positions []string
+			for _, day := range rec.days {
+				if day.business {
+					positions = append(positions, strconv.Itoa(day.n))
+				} else {
+					days = append(days, strconv.Itoa(day.n))
+				}
+			}
+			switch {
+			case len(days) > 0 && len(positions) > 0:
+				return "", "", "", fmt.Errorf("days mixed with business days of the month")
+			case len(positions) > 0 && byday != "":
+				return "", "", "", fmt.Errorf("business days of the month restricted to weekdays")
+			case len(positions) > 0:
+				parts = append(parts, "FREQ=MONTHLY", "BYDAY=MO,TU,WE,TH,FR", "BYSETPOS="+strings.Join(positions, ","))
+			default:
+				parts = append(parts, "FREQ=MONTHLY", "BYMONTHDAY="+strings.Join(days, ","))
+			}
+
+		case recurMonths:
+			parts = append(parts, "FREQ=DAILY")
+		}
+
+		if len(rec.months) > 0 {
+			var months []string
+			for _, m := range rec.months {
+				months = append(months, strconv.Itoa(int(m)))
+			}
+			parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+		}
+
+	case byday != "":
+		parts = append(parts, "FREQ=WEEKLY", "BYDAY="+byday)
+		byday = ""
+
+	default:
+		parts = append(parts, "FREQ=DAILY")
+	}
+
+	if byday != "" {
+		parts = append(parts, "BYDAY="+byday)
+	}
+
+	return strings.Join(parts, ";"), from, to, nil
+}
+
+// icalBounded returns true if the Schedule applies to a fixed range of dates
+func icalBounded(s Schedule) bool {
+	from, ok := s.DateFrom()
+	to, _ := s.DateTo()
+	return ok && !isAnnual(from) && to != maxDate
+}
+
+// icalFirst returns the first date from - to, inclusive, matched by s within
+// icalSearch days.  An empty to is unbounded
+func icalFirst(s Schedule, from, to string, loc *time.Location) (time.Time, bool) {
+	date, err := time.ParseInLocation(DateLayout, from, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	for i, date := 0, date.Add(12*time.Hour); i < icalSearch; i, date = i+1, date.AddDate(0, 0, 1) {
+		if to != "" && date.Format(DateLayout) > to {
+			break
+		}
+		if s.Contains(date) {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// icalDates calls fn with noon of each date from - to, inclusive.  Returns
+// false if the range is too long to enumerate
+func icalDates(from, to string, loc *time.Location, fn func(date time.Time)) bool {
+	date, err := time.ParseInLocation(DateLayout, from, loc)
+	if err != nil {
+		return false
+	}
+
+	for i, date := 0, date.Add(12*time.Hour); date.Format(DateLayout) <= to; i, date = i+1, date.AddDate(0, 0, 1) {
+		if i == icalSearch {
+			return false
+		}
+		fn(date)
+	}
+	return true
+}
+
+// icalTimezone returns the content lines of a VTIMEZONE for loc.  Each
+// transition in year becomes an observance recurring yearly on the same nth
+// weekday of the month; zones without transitions in year have a single
+// STANDARD observance
+func icalTimezone(loc *time.Location, year int) []string {
+	const day = 24 * 60 * 60
+
+	var (
+		from  = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+		to    = time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+		lines = []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+		found = false
+	)
+	for unix := from; unix < to; unix += day {
+		if zoneOffset(unix, loc) == zoneOffset(unix+day, loc) {
+			continue
+		}
+
+		// hi is the first second of the new offset
+		lo, hi := unix, unix+day
+		for hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			if zoneOffset(mid, loc) == zoneOffset(lo, loc) {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		lines = append(lines, icalObservance(loc, hi)...)
+		found = true
+	}
+
+	if !found {
+		name, offset := time.Unix(from, 0).In(loc).Zone()
+		lines = append(lines,
+			"BEGIN:STANDARD",
+			"DTSTART:19700101T000000",
+			"TZOFFSETFROM:"+icalOffset(int64(offset)),
+			"TZOFFSETTO:"+icalOffset(int64(offset)),
+			"TZNAME:"+name,
+			"END:STANDARD",
+		)
+	}
+
+	return append(lines, "END:VTIMEZONE")
+}
+
+// icalObservance returns the STANDARD or DAYLIGHT observance of the
+// transition of loc at the instant provided
+func icalObservance(loc *time.Location, unix int64) []string {
+	var (
+		offsetFrom     = zoneOffset(unix-1, loc)
+		name, offsetTo = time.Unix(unix, 0).In(loc).Zone()
+		wall           = time.Unix(unix+offsetFrom, 0).UTC() // wall clock before the transition
+		kind           = "STANDARD"
+		n              = (wall.Day()-1)/7 + 1
+	)
+	if int64(offsetTo) > offsetFrom {
+		kind = "DAYLIGHT"
+	}
+	if wall.AddDate(0, 0, 7).Month() != wall.Month() {
+		n = -1 // last weekday of the month
+	}
+
+	return []string{
+		"BEGIN:" + kind,
+		"DTSTART:" + wall.Format(icalDateTime),
+		"TZOFFSETFROM:" + icalOffset(offsetFrom),
+		"TZOFFSETTO:" + icalOffset(int64(offsetTo)),
+		"TZNAME:" + name,
+		fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%v;BYDAY=%v%v", int(wall.Month()), n, icalWeekdays(wall.Weekday())),
+		"END:" + kind,
+	}
+}
+
+// icalOffset formats an offset in seconds east of UTC e.g. -0500
+func icalOffset(seconds int64) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+
+	v := fmt.Sprintf("%v%02d%02d", sign, seconds/3600, seconds/60%60)
+	if s := seconds % 60; s != 0 {
+		v += fmt.Sprintf("%02d", s)
+	}
+	return v
+}
+
+// icalUntil returns the UNTIL of a rule ending on date.  Rules with a zone
+// must specify UNTIL in UTC
+func icalUntil(date string, zoned bool, loc *time.Location) string {
+	v, _ := time.ParseInLocation(DateLayout, date, loc)
+	v = v.AddDate(0, 0, 1).Add(-time.Second)
+	if zoned {
+		return v.UTC().Format(icalDateTime) + "Z"
+	}
+	return v.Format(icalDateTime)
+}
+
+// icalWall returns the wall clock time t on date.  Times beyond EndOfDay fall
+// on the following date
+func icalWall(date time.Time, t Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// icalWeekdays returns the weekdays as a BYDAY list e.g. MO,TU
+func icalWeekdays(weekdays ...time.Weekday) string {
+	var days []string
+	for _, w := range weekdays {
+		if d, ok := getDayOfTheWeek(w); ok {
+			days = append(days, strings.ToUpper(d.String()))
+		}
+	}
+	return strings.Join(days, ",")
+}
+
+// icalFold writes line terminated by CRLF, folding lines longer than icalLine
+func icalFold(buf *bytes.Buffer, line string) {
+	for n := icalLine; len(line) > n; n = icalLine - 1 {
+		buf.WriteString(line[:n])
+		buf.WriteString("\r\n ")
+		line = line[n:]
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func icalMax(a, b string) string {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// icalUnique removes adjacent duplicates from sorted values
+func icalUnique(values []string) []string {
+	var unique []string
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// ParseICalendar decodes the VEVENTs of an iCalendar file into Schedules.  An
+// event with an UNTIL or COUNT becomes a date range from DTSTART with the
+// hours of the event on each date matched by its RRULE; the date range is
+// split around EXDATEs.  Rules without an UNTIL or COUNT become regular hours,
+// which apply before DTSTART, and their EXDATEs become excludes of the
+// event's hours, or of the entire day for DATE values and EXDATEs marked
+// X-SCHEDULE-ALL-DAY=TRUE.  Yearly rules by date recur every year regardless of DTSTART.
+//
+// Events that cannot be represented, e.g. all day events or hourly rules, are
+// reported by an *ICalendarError along with the remaining Schedules
+func ParseICalendar(r io.Reader) (Schedules, error) {
+	events, err := icalEvents(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ss          Schedules
+		unsupported []string
+	)
+	for _, event := range events {
+		v, err := event.schedules()
+		if err != nil {
+			unsupported = append(unsupported, fmt.Sprintf("%v: %v", event.name(), err))
+			continue
+		}
+		ss = append(ss, v...)
+	}
+
+	// overrides already replace regular hours; an EXDATE of regular hours on
+	// the date of an override would also close the override.  All day
+	// closures are listed by each event open on the date
+	var (
+		filtered Schedules
+		seen     = map[string]bool{}
+	)
+	for _, s := range ss {
+		switch {
+		case !s.IsExclude():
+			filtered = append(filtered, s)
+		case seen[s.String()], icalOverridden(s, ss):
+		default:
+			seen[s.String()] = true
+			filtered = append(filtered, s)
+		}
+	}
+	ss = filtered
+
+	if len(unsupported) > 0 {
+		return ss, &ICalendarError{Unsupported: unsupported}
+	}
+	return ss, nil
+}
+
+// icalOverridden returns true if an include of ss overrides regular hours on
+// the date of the exclude
+func icalOverridden(ex Schedule, ss Schedules) bool {
+	dateFrom, _ := ex.DateFrom()
+	loc, ok := ex.Location()
+	if !ok {
+		loc = time.UTC
+	}
+	date, err := time.ParseInLocation(DateLayout, dateFrom, loc)
+	if err != nil {
+		return false
+	}
+
+	for _, s := range ss {
+		if !s.IsExclude() && s.Priority() == PriorityOverride && s.Contains(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// icalProperty is a content line e.g. DTSTART;TZID=America/New_York:20200106T090000
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// vevent holds the properties of a VEVENT by name
+type vevent map[string][]icalProperty
+
+// icalEvents reads the VEVENTs from r.  Components nested within a VEVENT,
+// e.g. VALARM, are ignored
+func icalEvents(r io.Reader) ([]vevent, error) {
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(r)
+	)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n := len(lines); n > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[n-1] += line[1:] // unfold
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		events []vevent
+		event  vevent
+		depth  int // depth of components nested within the current event
+	)
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		p, err := parseICalProperty(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case event == nil:
+			if p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") {
+				event = vevent{}
+			}
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END" && depth > 0:
+			depth--
+		case p.name == "END":
+			events = append(events, event)
+			event = nil
+		case depth == 0:
+			event[p.name] = append(event[p.name], p)
+		}
+	}
+
+	return events, nil
+}
+
+func parseICalProperty(line string) (icalProperty, error) {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ':' && !quoted:
+			parts := strings.Split(line[:i], ";")
+			p := icalProperty{
+				name:   strings.ToUpper(parts[0]),
+				params: map[string]string{},
+				value:  line[i+1:],
+			}
+			for _, param := range parts[1:] {
+				if j := strings.Index(param, "="); j > 0 {
+					p.params[strings.ToUpper(param[:j])] = strings.Trim(param[j+1:], `"`)
+				}
+			}
+			return p, nil
+		}
+	}
+	return icalProperty{}, fmt.Errorf("invalid iCalendar line, %v", line)
+}
+
+func (e vevent) get(name string) (icalProperty, bool) {
+	if pp := e[name]; len(pp) > 0 {
+		return pp[0], true
+	}
+	return icalProperty{}, false
+}
+
+// name identifies the event in errors
+func (e vevent) name() string {
+	for _, name := range []string{"UID", "SUMMARY"} {
+		if p, ok := e.get(name); ok {
+			return p.value
+		}
+	}
+	return "VEVENT"
+}
+
+// icalRuleParts holds the RRULE parts understood by ParseICalendar
+var icalRuleParts = map[string]bool{
+	"FREQ":       true,
+	"INTERVAL":   true,
+	"UNTIL":      true,
+	"COUNT":      true,
+	"WKST":       true,
+	"BYDAY":      true,
+	"BYMONTH":    true,
+	"BYMONTHDAY": true,
+	"BYSETPOS":   true,
+}
+
+// schedules converts the event into Schedules
+func (e vevent) schedules() (Schedules, error) {
+	for _, name := range []string{"RDATE", "EXRULE", "RECURRENCE-ID"} {
+		if _, ok := e.get(name); ok {
+			return nil, fmt.Errorf("unsupported property, %v", name)
+		}
+	}
+
+	dtstart, ok := e.get("DTSTART")
+	if !ok {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+	if dtstart.params["VALUE"] == "DATE" || len(dtstart.value) == len(icalDate) {
+		return nil, fmt.Errorf("unsupported all day event")
+	}
+	start, zoned, err := icalTime(dtstart, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		d    time.Duration
+		from = NewTimeFromDate(start)
+	)
+	if p, ok := e.get("DTEND"); ok {
+		end, _, err := icalTime(p, start.Location())
+		if err != nil {
+			return nil, err
+		}
+		// measured on the wall clock, as with TimeSlot.Duration
+		d = icalWall(end.In(start.Location()), NewTimeFromDate(end.In(start.Location()))).Sub(icalWall(start, from))
+	} else if p, ok := e.get("DURATION"); ok {
+		if d, err = icalDuration(p.value); err != nil {
+			return nil, err
+		}
+	}
+	if d < time.Minute || d >= 24*time.Hour || d%time.Minute != 0 || start.Second() != 0 {
+		return nil, fmt.Errorf("unsupported event time, %v for %v", start.Format("15:04:05"), d)
+	}
+
+	var (
+		to    = from.Add(d)
+		date  = start.Format(DateLayout)
+		until = maxDate
+		build = func(dateFrom, dateTo string, r Recurrence, weekdays ...time.Weekday) Schedule {
+			s := DateRange(dateFrom, dateTo, from, to, weekdays...)
+			if r != "" {
+				s = s.Recur(r)
+			}
+			if zoned {
+				s = s.In(start.Location())
+			}
+			return s
+		}
+		exclude = func(date string, allDay bool) Schedule {
+			s := ExcludeTimeRange(date, date, from, to)
+			if allDay {
+				s = ExcludeDateRange(date, date)
+			}
+			if zoned {
+				s = s.In(start.Location())
+			}
+			return s
+		}
+	)
+
+	p, ok := e.get("RRULE")
+	if !ok {
+		return Schedules{build(date, date, "")}, nil
+	}
+
+	rule, err := parseRRule(p.value)
+	if err != nil {
+		return nil, err
+	}
+
+	// pattern is a set of dates matched by the rule
+	type pattern struct {
+		annual   string
+		weekdays []time.Weekday
+		r        Recurrence
+	}
+	var patterns []pattern
+
+	interval := 1
+	if v, ok := rule["INTERVAL"]; ok {
+		if interval, err = strconv.Atoi(v); err != nil || interval < 1 {
+			return nil, fmt.Errorf("invalid INTERVAL, %v", v)
+		}
+	}
+
+	weekdays, ordinals, err := icalParseWeekdays(rule["BYDAY"])
+	if err != nil {
+		return nil, err
+	}
+
+	var months []time.Month
+	if v, ok := rule["BYMONTH"]; ok {
+		values, err := icalInts(v, 12)
+		if err != nil {
+			return nil, err
+		}
+		for _, month := range values {
+			if month < 0 {
+				return nil, fmt.Errorf("invalid BYMONTH, %v", v)
+			}
+			months = append(months, time.Month(month))
+		}
+	}
+
+	var (
+		has = func(parts ...string) bool {
+			for _, part := range parts {
+				if _, ok := rule[part]; ok {
+					return true
+				}
+			}
+			return false
+		}
+		nth = func(months []time.Month) {
+			for _, month := range months {
+				for _, o := range ordinals {
+					patterns = append(patterns, pattern{r: NthWeekday(month, o.n, o.weekday, 0)})
+				}
+			}
+		}
+	)
+
+	switch freq := rule["FREQ"]; {
+	case freq == "DAILY" && !has("BYMONTHDAY", "BYSETPOS") && len(ordinals) == 0:
+		v := pattern{weekdays: weekdays}
+		if interval > 1 {
+			v.r = EveryDays(interval, date)
+		}
+		patterns = append(patterns, v)
+
+	case freq == "WEEKLY" && !has("BYMONTHDAY", "BYSETPOS") && len(ordinals) == 0:
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{start.Weekday()}
+		}
+		v := pattern{weekdays: weekdays}
+		if interval > 1 {
+			wkst := []time.Weekday{time.Monday}
+			if s, ok := rule["WKST"]; ok {
+				if wkst, _, err = icalParseWeekdays(s); err != nil || len(wkst) != 1 {
+					return nil, fmt.Errorf("invalid WKST, %v", s)
+				}
+			}
+			// weeks begin on WKST
+			anchor := start.AddDate(0, 0, -((int(start.Weekday()) - int(wkst[0]) + 7) % 7))
+			v.r = EveryWeeks(interval, anchor.Format(DateLayout))
+		}
+		patterns = append(patterns, v)
+
+	case freq == "MONTHLY" && interval == 1:
+		switch {
+		case has("BYSETPOS") && icalBusinessDays(weekdays) && len(ordinals) == 0 && !has("BYMONTHDAY"):
+			positions, err := icalInts(rule["BYSETPOS"], 23)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, pattern{r: BusinessDaysOfMonth(positions...)})
+
+		case len(ordinals) > 0 && len(weekdays) == 0 && !has("BYMONTHDAY", "BYSETPOS"):
+			if months == nil {
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}
+			nth(months)
+			months = nil // selected by the nth weekdays
+
+		case len(ordinals) == 0 && !has("BYSETPOS"):
+			days := []int{start.Day()}
+			if has("BYMONTHDAY") {
+				if days, err = icalInts(rule["BYMONTHDAY"], 31); err != nil {
+					return nil, err
+				}
+			}
+			patterns = append(patterns, pattern{weekdays: weekdays, r: DaysOfMonth(days...)})
+
+		default:
+			return nil, fmt.Errorf("unsupported RRULE, %v", p.value)
+		}
+
+	case freq == "YEARLY" && interval == 1 && !has("BYSETPOS"):
+		if months == nil {
+			months = []time.Month{start.Month()}
+		}
+
+		switch {
+		case len(ordinals) > 0 && len(weekdays) == 0 && !has("BYMONTHDAY"):
+			nth(months)
+
+		case len(ordinals) == 0 && !has("UNTIL", "COUNT"):
+			days := []int{start.Day()}
+			if has("BYMONTHDAY") {
+				if days, err = icalInts(rule["BYMONTHDAY"], 31); err != nil {
+					return nil, err
+				}
+			}
+			for _, month := range months {
+				for _, day := range days {
+					if day < 0 {
+						return nil, fmt.Errorf("unsupported RRULE, %v", p.value)
+					}
+					patterns = append(patterns, pattern{annual: Annual(month, day), weekdays: weekdays})
+				}
+			}
+
+		default:
+			return nil, fmt.Errorf("unsupported RRULE, %v", p.value)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported RRULE, %v", p.value)
+	}
+
+	// BYMONTH limits the dates of other frequencies
+	if rule["FREQ"] != "YEARLY" && len(months) > 0 {
+		for i := range patterns {
+			patterns[i].r = patterns[i].r.InMonths(months...)
+		}
+	}
+
+	if v, ok := rule["UNTIL"]; ok {
+		if until, err = icalUntilDate(v, start); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := rule["COUNT"]; ok {
+		count, err := strconv.Atoi(v)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid COUNT, %v", v)
+		}
+
+		var ss Schedules
+		for _, v := range patterns {
+			ss = append(ss, build(date, until, v.r, v.weekdays...))
+		}
+		if until, err = icalCount(ss, start, count); err != nil {
+			return nil, err
+		}
+	}
+
+	// split the date range around each EXDATE; excluding the event's hours
+	// instead would also close other events on the same date
+	var (
+		exdates []string
+		closed  = map[string]bool{} // dates closed the entire day
+	)
+	for _, p := range e["EXDATE"] {
+		allDay := p.params["VALUE"] == "DATE" || strings.EqualFold(p.params[icalAllDay], "TRUE")
+		for _, value := range strings.Split(p.value, ",") {
+			p.value = value
+			t, _, err := icalTime(p, start.Location())
+			if err != nil {
+				return nil, err
+			}
+			if v := t.In(start.Location()).Format(DateLayout); v >= date && v <= until {
+				exdates = append(exdates, v)
+				closed[v] = closed[v] || allDay || len(value) == len(icalDate)
+			}
+		}
+	}
+	sort.Strings(exdates)
+
+	var ss Schedules
+	for _, v := range patterns {
+		if v.annual != "" {
+			if len(exdates) > 0 {
+				return nil, fmt.Errorf("unsupported EXDATE of yearly rule")
+			}
+			ss = append(ss, build(v.annual, v.annual, v.r, v.weekdays...))
+			continue
+		}
+
+		if until == maxDate {
+			// open ended rules are regular hours; a date range would
+			// override the regular hours of other events
+			ss = append(ss, build("", "", v.r, v.weekdays...))
+			continue
+		}
+
+		next := date
+		for _, exdate := range icalUnique(exdates) {
+			if next < exdate {
+				ss = append(ss, build(next, icalAddDate(exdate, -1), v.r, v.weekdays...))
+			}
+			next = icalAddDate(exdate, 1)
+		}
+		if next <= until {
+			ss = append(ss, build(next, until, v.r, v.weekdays...))
+		}
+	}
+
+	if until == maxDate {
+		for _, exdate := range icalUnique(exdates) {
+			ss = append(ss, exclude(exdate, closed[exdate]))
+		}
+	}
+
+	return ss, nil
+}
+
+// icalAddDate adds days to date, YYYY-MM-DD
+func icalAddDate(date string, days int) string {
+	t, _ := time.Parse(DateLayout, date)
+	return t.AddDate(0, 0, days).Format(DateLayout)
+}
+
+// parseRRule splits an RRULE into its parts
+func parseRRule(value string) (map[string]string, error) {
+	rule := map[string]string{}
+	for _, part := range strings.Split(value, ";") {
+		i := strings.Index(part, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid RRULE, %v", value)
+		}
+		name := strings.ToUpper(part[:i])
+		if !icalRuleParts[name] {
+			return nil, fmt.Errorf("unsupported RRULE part, %v", name)
+		}
+		rule[name] = strings.ToUpper(part[i+1:])
+	}
+	return rule, nil
+}
+
+// icalOrdinal is an nth weekday from BYDAY e.g. -1MO
+type icalOrdinal struct {
+	n       int
+	weekday time.Weekday
+}
+
+// icalParseWeekdays parses a BYDAY list into plain weekdays and nth weekdays
+func icalParseWeekdays(value string) ([]time.Weekday, []icalOrdinal, error) {
+	if value == "" {
+		return nil, nil, nil
+	}
+
+	var (
+		weekdays []time.Weekday
+		ordinals []icalOrdinal
+	)
+	for _, v := range strings.Split(value, ",") {
+		if len(v) < 2 {
+			return nil, nil, fmt.Errorf("invalid BYDAY, %v", value)
+		}
+
+		code := v[len(v)-2:]
+		d, ok := getDayOfTheWeekBytes([]byte(code[:1] + strings.ToLower(code[1:])))
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid BYDAY, %v", value)
+		}
+		weekday, _ := d.Weekday()
+
+		if v = v[:len(v)-2]; v == "" {
+			weekdays = append(weekdays, weekday)
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return nil, nil, fmt.Errorf("unsupported BYDAY, %v", value)
+		}
+		ordinals = append(ordinals, icalOrdinal{n: n, weekday: weekday})
+	}
+
+	return weekdays, ordinals, nil
+}
+
+// icalBusinessDays returns true if weekdays are exactly Monday through Friday
+func icalBusinessDays(weekdays []time.Weekday) bool {
+	return icalWeekdays(weekdays...) == "MO,TU,WE,TH,FR"
+}
+
+// icalInts parses a list of non-zero integers between -limit and limit
+func icalInts(value string, limit int) ([]int, error) {
+	var ints []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(v, "+"))
+		if err != nil || n == 0 || n < -limit || n > limit {
+			return nil, fmt.Errorf("invalid list, %v", value)
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+// icalTime parses a DATE or DATE-TIME property value.  Floating times are
+// parsed in loc.  Returns true if the time is bound to a zone
+func icalTime(p icalProperty, loc *time.Location) (time.Time, bool, error) {
+	value := p.value
+	switch tzid := p.params["TZID"]; {
+	case len(value) == len(icalDate):
+		t, err := time.ParseInLocation(icalDate, value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date, %v", value)
+		}
+		return t, false, nil
+
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(icalDateTime, strings.TrimSuffix(value, "Z"))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time, %v", value)
+		}
+		return t, true, nil
+
+	case tzid != "":
+		zone, ok := loadLocation(tzid)
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("unknown zone, %v", tzid)
+		}
+		loc = zone
+		fallthrough
+
+	default:
+		t, err := time.ParseInLocation(icalDateTime, value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time, %v", value)
+		}
+		return t, tzid != "", nil
+	}
+}
+
+// icalUntilDate returns the last date, YYYY-MM-DD, included by UNTIL for a
+// rule starting at start
+func icalUntilDate(value string, start time.Time) (string, error) {
+	until, _, err := icalTime(icalProperty{value: value}, start.Location())
+	if err != nil {
+		return "", err
+	}
+
+	until = until.In(start.Location())
+	if len(value) > len(icalDate) && NewTimeFromDate(until) < NewTimeFromDate(start) {
+		until = until.AddDate(0, 0, -1) // ends before the event starts on that date
+	}
+	return until.Format(DateLayout), nil
+}
+
+// icalCount returns the date of the count-th occurrence of the Schedules
+// from start
+func icalCount(ss Schedules, start time.Time, count int) (string, error) {
+	date := time.Date(start.Year(), start.Month(), start.Day(), 12, 0, 0, 0, start.Location())
+	for i := 0; i < 100*366; i, date = i+1, date.AddDate(0, 0, 1) {
+		if ContainsDate(date, ss...) {
+			if count--; count == 0 {
+				return date.Format(DateLayout), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unsupported COUNT")
+}
+
+// icalDuration parses a DURATION e.g. PT1H30M
+func icalDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid DURATION, %v", value)
+	if !strings.HasPrefix(value, "P") {
+		return 0, invalid
+	}
+
+	var (
+		d      time.Duration
+		n      int
+		digits bool
+		clock  bool
+	)
+	for _, c := range value[1:] {
+		switch {
+		case c >= '0' && c <= '9':
+			n, digits = n*10+int(c-'0'), true
+			continue
+		case c == 'T' && !digits:
+			clock = true
+			continue
+		case !digits:
+			return 0, invalid
+		case c == 'W' && !clock:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case c == 'D' && !clock:
+			d += time.Duration(n) * 24 * time.Hour
+		case c == 'H' && clock:
+			d += time.Duration(n) * time.Hour
+		case c == 'M' && clock:
+			d += time.Duration(n) * time.Minute
+		case c == 'S' && clock:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, invalid
+		}
+		n, digits = 0, false
+	}
+	if digits {
+		return 0, invalid
+	}
+	return d, nil
+}
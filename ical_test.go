@@ -0,0 +1,555 @@
+package schedule
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func icalLines(lines ...string) string {
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestSchedules_MarshalICalendar(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	var (
+		start    = time.Date(2020, 1, 1, 0, 0, 0, 0, loc)
+		weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	)
+
+	t.Run("weekly", func(t *testing.T) {
+		ss := Schedules{
+			New(900, 1700, weekdays...),
+			New(1800, 200, time.Saturday),
+			ExcludeDateRange("2020-01-20", "2020-01-20"),
+			DateRange("2020-12-24", "2020-12-24", 900, 1200),
+			DateRange("2020-03-01", "2020-03-31", 1000, 1400, time.Sunday),
+		}.In(loc)
+
+		data, err := ss.MarshalICalendar(start)
+		assert.Nil(t, err)
+		assert.Equal(t, icalLines(
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//savaki//schedule//EN",
+			"BEGIN:VTIMEZONE",
+			"TZID:America/New_York",
+			"BEGIN:DAYLIGHT",
+			"DTSTART:20200308T020000",
+			"TZOFFSETFROM:-0500",
+			"TZOFFSETTO:-0400",
+			"TZNAME:EDT",
+			"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU",
+			"END:DAYLIGHT",
+			"BEGIN:STANDARD",
+			"DTSTART:20201101T020000",
+			"TZOFFSETFROM:-0400",
+			"TZOFFSETTO:-0500",
+			"TZNAME:EST",
+			"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU",
+			"END:STANDARD",
+			"END:VTIMEZONE",
+			"BEGIN:VEVENT",
+			"UID:40d5f9afc74f2844@schedule",
+			"DTSTAMP:20200101T050000Z",
+			"DTSTART;TZID=America/New_York:20200101T090000",
+			"DTEND;TZID=America/New_York:20200101T170000",
+			"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			"EXDATE;TZID=America/New_York:20201224T090000",
+			"EXDATE;TZID=America/New_York;X-SCHEDULE-ALL-DAY=TRUE:20200120T090000",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"UID:93493adafb6212c3@schedule",
+			"DTSTAMP:20200101T050000Z",
+			"DTSTART;TZID=America/New_York:20200104T180000",
+			"DTEND;TZID=America/New_York:20200105T020000",
+			"RRULE:FREQ=WEEKLY;BYDAY=SA",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"UID:929f5ddfd51a32ec@schedule",
+			"DTSTAMP:20200101T050000Z",
+			"DTSTART;TZID=America/New_York:20201224T090000",
+			"DTEND;TZID=America/New_York:20201224T120000",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"UID:b702ffb822581103@schedule",
+			"DTSTAMP:20200101T050000Z",
+			"DTSTART;TZID=America/New_York:20200301T100000",
+			"DTEND;TZID=America/New_York:20200301T140000",
+			"RRULE:FREQ=WEEKLY;BYDAY=SU;UNTIL=20200401T035959Z",
+			"END:VEVENT",
+			"END:VCALENDAR",
+		), string(data))
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Want     string
+		}{
+			"daily": {
+				Schedule: New(900, 1700),
+				Want:     "FREQ=DAILY",
+			},
+			"annual": {
+				Schedule: DateRange(Annual(time.July, 4), Annual(time.July, 4), 900, 1200),
+				Want:     "FREQ=YEARLY;BYMONTH=7;BYMONTHDAY=4",
+			},
+			"nth weekday": {
+				Schedule: New(900, 1200).Recur(NthWeekday(time.May, -1, time.Monday, 0)),
+				Want:     "FREQ=YEARLY;BYMONTH=5;BYDAY=-1MO",
+			},
+			"every other saturday": {
+				Schedule: New(900, 1200).Recur(EveryWeeks(2, "2020-01-04")),
+				Want:     "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA;WKST=SA",
+			},
+			"every third day": {
+				Schedule: New(900, 1200, time.Monday).Recur(EveryDays(3, "2020-01-04")),
+				Want:     "FREQ=DAILY;INTERVAL=3;BYDAY=MO",
+			},
+			"days of month": {
+				Schedule: New(900, 1200).Recur(DaysOfMonth(1, 15, -1)),
+				Want:     "FREQ=MONTHLY;BYMONTHDAY=1,15,-1",
+			},
+			"last business day": {
+				Schedule: New(900, 1200).Recur(BusinessDaysOfMonth(-1)),
+				Want:     "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			},
+			"months": {
+				Schedule: New(900, 1200, time.Saturday).Recur(Months(time.June, time.July)),
+				Want:     "FREQ=DAILY;BYMONTH=6,7;BYDAY=SA",
+			},
+			"days of months": {
+				Schedule: New(900, 1200).Recur(DaysOfMonth(1).InMonths(time.March)),
+				Want:     "FREQ=MONTHLY;BYMONTHDAY=1;BYMONTH=3",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				got, _, _, err := icalRule(tc.Schedule)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Want, got)
+			})
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		regular := New(900, 1700, weekdays...)
+		testCases := map[string]struct {
+			Schedules Schedules
+			Want      string
+		}{
+			"open ended exclude": {
+				Schedules: Schedules{regular, ExcludeDateRange("", "", time.Friday)},
+				Want:      "1:::0000:0000:Fr:exclude: exclude requires a date range",
+			},
+			"partial exclude": {
+				Schedules: Schedules{regular, ExcludeTimeRange("2020-01-06", "2020-01-06", 1200, 1300)},
+				Want:      "1:2020-01-06:2020-01-06:1200:1300::exclude: exclude covers part of 09:00 - 17:00",
+			},
+			"nth weekday offset": {
+				Schedules: Schedules{New(900, 1200).Recur(NthWeekday(time.November, 4, time.Thursday, 1))},
+				Want:      "4:::0900:1200::::nth=11/4Th+1: nth weekday offsets",
+			},
+			"annual range": {
+				Schedules: Schedules{DateRange(Annual(time.December, 24), Annual(time.January, 2), 900, 1200)},
+				Want:      "3:--12-24:--01-02:0900:1200::: annual date ranges",
+			},
+			"annual override": {
+				Schedules: Schedules{regular, DateRange(Annual(time.July, 4), Annual(time.July, 4), 900, 1200)},
+				Want:      "3:--07-04:--07-04:0900:1200::: override of regular hours requires a bounded date range",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				data, err := tc.Schedules.MarshalICalendar(start)
+
+				var ice *ICalendarError
+				assert.True(t, errors.As(err, &ice))
+				assert.Contains(t, ice.Unsupported, tc.Want)
+				assert.Contains(t, string(data), "BEGIN:VCALENDAR")
+			})
+		}
+	})
+
+	t.Run("timezones", func(t *testing.T) {
+		testCases := map[string]struct {
+			Zone string
+			Want []string
+		}{
+			"southern hemisphere": {
+				Zone: "Australia/Lord_Howe",
+				Want: []string{
+					"BEGIN:VTIMEZONE",
+					"TZID:Australia/Lord_Howe",
+					"BEGIN:STANDARD",
+					"DTSTART:20200405T020000",
+					"TZOFFSETFROM:+1100",
+					"TZOFFSETTO:+1030",
+					"TZNAME:+1030",
+					"RRULE:FREQ=YEARLY;BYMONTH=4;BYDAY=1SU",
+					"END:STANDARD",
+					"BEGIN:DAYLIGHT",
+					"DTSTART:20201004T020000",
+					"TZOFFSETFROM:+1030",
+					"TZOFFSETTO:+1100",
+					"TZNAME:+11",
+					"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=1SU",
+					"END:DAYLIGHT",
+					"END:VTIMEZONE",
+				},
+			},
+			"last sunday": {
+				Zone: "Europe/London",
+				Want: []string{
+					"BEGIN:VTIMEZONE",
+					"TZID:Europe/London",
+					"BEGIN:DAYLIGHT",
+					"DTSTART:20200329T010000",
+					"TZOFFSETFROM:+0000",
+					"TZOFFSETTO:+0100",
+					"TZNAME:BST",
+					"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+					"END:DAYLIGHT",
+					"BEGIN:STANDARD",
+					"DTSTART:20201025T020000",
+					"TZOFFSETFROM:+0100",
+					"TZOFFSETTO:+0000",
+					"TZNAME:GMT",
+					"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+					"END:STANDARD",
+					"END:VTIMEZONE",
+				},
+			},
+			"no daylight saving": {
+				Zone: "Asia/Kolkata",
+				Want: []string{
+					"BEGIN:VTIMEZONE",
+					"TZID:Asia/Kolkata",
+					"BEGIN:STANDARD",
+					"DTSTART:19700101T000000",
+					"TZOFFSETFROM:+0530",
+					"TZOFFSETTO:+0530",
+					"TZNAME:IST",
+					"END:STANDARD",
+					"END:VTIMEZONE",
+				},
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				loc, err := time.LoadLocation(tc.Zone)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Want, icalTimezone(loc, 2020))
+			})
+		}
+	})
+
+	t.Run("fold", func(t *testing.T) {
+		var exdates []Schedule
+		for day := 1; day <= 28; day++ {
+			date := time.Date(2020, 2, day, 0, 0, 0, 0, time.UTC).Format(DateLayout)
+			exdates = append(exdates, ExcludeDateRange(date, date))
+		}
+
+		data, err := append(Schedules{New(900, 1700)}, exdates...).MarshalICalendar(start)
+		assert.Nil(t, err)
+		for _, line := range strings.Split(string(data), "\r\n") {
+			assert.True(t, len(line) <= icalLine, line)
+		}
+
+		want := []string{"1:::0900:1700::"}
+		for _, ex := range exdates {
+			want = append(want, ex.String())
+		}
+
+		ss, err := ParseICalendar(strings.NewReader(string(data)))
+		assert.Nil(t, err)
+		assert.Equal(t, want, ss.StringSlice())
+	})
+}
+
+func TestParseICalendar(t *testing.T) {
+	const calendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Calendar//EN
+BEGIN:VTIMEZONE
+TZID:America/New_York
+BEGIN:STANDARD
+DTSTART:19701101T020000
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0500
+END:STANDARD
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:weekdays
+DTSTART;TZID=America/New_York:20200106T090000
+DTEND;TZID=America/New_York:20200106T170000
+RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20201231T235959Z
+EXDATE;TZID=America/New_York:20200120T090000
+EXDATE;TZID=America/New_York:20200217T090000
+BEGIN:VALARM
+ACTION:DISPLAY
+TRIGGER:-PT15M
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:late
+DTSTART:20200104T180000
+DURATION:PT8H
+RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=3
+END:VEVENT
+BEGIN:VEVENT
+UID:billing
+DTSTART:20200131T100000
+DTEND:20200131T140000
+RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1
+END:VEVENT
+BEGIN:VEVENT
+UID:memorial
+DTSTART:20200525T100000
+DTEND:20200525T120000
+RRULE:FREQ=YEARLY;BYMONTH=5;BYDAY=-1MO
+END:VEVENT
+BEGIN:VEVENT
+UID:independence
+DTSTART:20200704T100000
+DTEND:20200704T120000
+RRULE:FREQ=YEARLY
+END:VEVENT
+BEGIN:VEVENT
+UID:party
+SUMMARY:Holiday party
+DTSTART:20201218T170000Z
+DTEND:20201218T2100
+ 00Z
+END:VEVENT
+BEGIN:VEVENT
+UID:hourly
+DTSTART:20200101T090000
+DTEND:20200101T093000
+RRULE:FREQ=HOURLY
+END:VEVENT
+BEGIN:VEVENT
+UID:all-day
+DTSTART;VALUE=DATE:20200101
+DTEND;VALUE=DATE:20200102
+END:VEVENT
+BEGIN:VEVENT
+UID:by-hour
+DTSTART:20200101T090000
+DTEND:20200101T093000
+RRULE:FREQ=DAILY;BYHOUR=9,12
+END:VEVENT
+END:VCALENDAR
+`
+
+	ss, err := ParseICalendar(strings.NewReader(calendar))
+
+	var ice *ICalendarError
+	assert.True(t, errors.As(err, &ice))
+	assert.Equal(t, []string{
+		"hourly: unsupported RRULE, FREQ=HOURLY",
+		"all-day: unsupported all day event",
+		"by-hour: unsupported RRULE part, BYHOUR",
+	}, ice.Unsupported)
+
+	assert.Equal(t, []string{
+		"2:2020-01-06:2020-01-19:0900:1700:MoTuWeThFr::America/New_York",
+		"2:2020-01-21:2020-02-16:0900:1700:MoTuWeThFr::America/New_York",
+		"2:2020-02-18:2020-12-31:0900:1700:MoTuWeThFr::America/New_York",
+		"4:2020-01-04:2020-02-01:1800:0200:Sa:::every=2w/2019-12-30",
+		"4:::1000:1400::::monthly=-1b",
+		"4:::1000:1200::::nth=5/-1Mo",
+		"3:--07-04:--07-04:1000:1200::",
+		"2:2020-12-18:2020-12-18:1700:2100:::UTC",
+	}, ss.StringSlice())
+
+	t.Run("hours", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		assert.Nil(t, err)
+
+		testCases := map[string]struct {
+			Date string
+			Want []TimeSlot
+		}{
+			"weekday":          {Date: "2020-01-07", Want: []TimeSlot{NewTimeSlot(900, 1700)}},
+			"exdate":           {Date: "2020-02-17", Want: nil},
+			"every other week": {Date: "2020-01-18", Want: []TimeSlot{NewTimeSlot(1800, 2600)}},
+			"overnight":        {Date: "2020-01-19", Want: []TimeSlot{NewTimeSlot(0, 200)}},
+			"off week":         {Date: "2020-01-11", Want: nil},
+			"after count":      {Date: "2020-02-15", Want: nil},
+			"last business":    {Date: "2021-01-29", Want: []TimeSlot{NewTimeSlot(1000, 1400)}},
+			"before dtstart":   {Date: "2019-12-31", Want: []TimeSlot{NewTimeSlot(1000, 1400)}},
+			"memorial day":     {Date: "2022-05-30", Want: []TimeSlot{NewTimeSlot(1000, 1200)}},
+			"annual":           {Date: "2019-07-04", Want: []TimeSlot{NewTimeSlot(1000, 1200)}},
+			"after until":      {Date: "2021-01-04", Want: nil},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				date, err := time.ParseInLocation(DateLayout, tc.Date, loc)
+				assert.Nil(t, err)
+
+				got, err := ss.TimeSlots(date)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Want, got)
+			})
+		}
+	})
+
+	t.Run("regular hours", func(t *testing.T) {
+		const calendar = `BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:mornings
+DTSTART:20200106T090000
+DTEND:20200106T120000
+RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR
+EXDATE:20200114T090000
+END:VEVENT
+BEGIN:VEVENT
+UID:inventory
+DTSTART:20200115T130000
+DTEND:20200115T150000
+END:VEVENT
+END:VCALENDAR
+`
+
+		imported, err := ParseICalendar(strings.NewReader(calendar))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"1:::0900:1200:MoTuWeThFr:",
+			"1:2020-01-14:2020-01-14:0900:1200::exclude",
+			"1:2020-01-15:2020-01-15:1300:1500::",
+		}, imported.StringSlice())
+
+		// imported hours combine with regular hours rather than replacing them
+		ss := append(imported, New(1300, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday))
+
+		testCases := map[string]struct {
+			Date time.Time
+			Want []TimeSlot
+		}{
+			"regular": {
+				Date: time.Date(2020, 1, 13, 0, 0, 0, 0, time.UTC),
+				Want: []TimeSlot{NewTimeSlot(900, 1200), NewTimeSlot(1300, 1700)},
+			},
+			"exdate": {
+				Date: time.Date(2020, 1, 14, 0, 0, 0, 0, time.UTC),
+				Want: []TimeSlot{NewTimeSlot(1300, 1700)},
+			},
+			"override": {
+				Date: time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC),
+				Want: []TimeSlot{NewTimeSlot(1300, 1500)},
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				got, err := ss.TimeSlots(tc.Date)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Want, got)
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseICalendar(strings.NewReader("BEGIN:VCALENDAR\r\nnot a property\r\n"))
+		assert.EqualError(t, err, "invalid iCalendar line, not a property")
+	})
+}
+
+func TestICalendar_roundTrip(t *testing.T) {
+	loc, err := time.LoadLocation("America/Chicago")
+	assert.Nil(t, err)
+
+	var (
+		start = time.Date(2020, 1, 1, 0, 0, 0, 0, loc)
+		want  = Schedules{
+			New(800, 1800, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday),
+			New(2200, 300, time.Friday, time.Saturday),
+			New(1000, 1400).Recur(EveryWeeks(2, "2020-01-04")),
+			New(900, 1200).Recur(DaysOfMonth(1, -1)),
+			New(1300, 1500).Recur(BusinessDaysOfMonth(1)),
+			New(700, 800, time.Sunday).Recur(Months(time.June, time.July)),
+			New(1600, 1700).Recur(DaysOfMonth(10).InMonths(time.March)),
+			ExcludeDateRange("2020-07-03", "2020-07-05"),
+			ExcludeDateRange("2020-12-24", "2020-12-26", time.Thursday, time.Friday),
+			DateRange("2020-11-27", "2020-11-27", 1000, 1500),
+		}.In(loc)
+	)
+
+	data, err := want.MarshalICalendar(start)
+	assert.Nil(t, err)
+
+	got, err := ParseICalendar(strings.NewReader(string(data)))
+	assert.Nil(t, err)
+
+	for date := start; date.Year() == 2020; date = date.AddDate(0, 0, 1) {
+		expected, err := want.TimeSlots(date)
+		assert.Nil(t, err)
+
+		actual, err := got.TimeSlots(date)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, date.Format(DateLayout))
+	}
+}
+
+func TestICalendar_roundTripSchedules(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	var (
+		start = time.Date(2020, 1, 1, 0, 0, 0, 0, loc)
+		want  = Schedules{
+			New(900, 1200, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday),
+			ExcludeDateRange("2020-07-27", "2020-07-27"),
+			New(1300, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday),
+		}.In(loc)
+	)
+
+	data, err := want.MarshalICalendar(start)
+	assert.Nil(t, err)
+
+	got, err := ParseICalendar(strings.NewReader(string(data)))
+	assert.Nil(t, err)
+	assert.Equal(t, want.StringSlice(), got.StringSlice())
+}
+
+func TestICalDuration(t *testing.T) {
+	testCases := map[string]struct {
+		Value string
+		Want  time.Duration
+		Err   bool
+	}{
+		"hours":   {Value: "PT8H", Want: 8 * time.Hour},
+		"minutes": {Value: "PT1H30M", Want: 90 * time.Minute},
+		"days":    {Value: "P1D", Want: 24 * time.Hour},
+		"weeks":   {Value: "P1W", Want: 7 * 24 * time.Hour},
+		"missing": {Value: "PT", Want: 0},
+		"prefix":  {Value: "T1H", Err: true},
+		"unit":    {Value: "PT1X", Err: true},
+		"digits":  {Value: "PT1", Err: true},
+		"order":   {Value: "P1H", Err: true},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := icalDuration(tc.Value)
+			if tc.Err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
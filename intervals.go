@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"time"
+)
+
+// Interval is an absolute range of time from Start (inclusive) to End
+// (exclusive)
+type Interval struct {
+	Start time.Time // Start of the interval, inclusive
+	End   time.Time // End of the interval, exclusive
+}
+
+// Duration of the Interval
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// Iter iterates over the open intervals of Schedules in chronological order.
+// Intervals that abut or overlap, e.g. overnight hours followed by the next
+// day's hours, are merged.  Continuous open time longer than the horizon is
+// split into consecutive intervals.
+type Iter struct {
+	ss      Schedules
+	r       resolver
+	from    time.Time // intervals are clipped to start no earlier than from
+	until   time.Time // optional; dates after until are not loaded
+	date    time.Time // date to load next
+	horizon int       // days without intervals before iteration stops
+	idle    int
+	queue   []Interval
+	pending *Interval
+	err     error
+}
+
+// Iter returns an iterator over the open intervals starting from the time
+// provided.  Iteration stops once no intervals are found within the horizon;
+// see WithHorizon.
+func (s Schedules) Iter(from time.Time, opts ...Option) *Iter {
+	var (
+		o    = buildOptions(opts...)
+		date = alignMidnight(localize(from, s...))
+	)
+
+	return &Iter{
+		ss:      s,
+		r:       newResolver(s),
+		from:    localize(from, s...),
+		date:    date.AddDate(0, 0, -1), // previous overnight hours may still be open
+		horizon: o.horizon,
+	}
+}
+
+// Err returns the first error encountered during iteration, if any
+func (it *Iter) Err() error {
+	return it.err
+}
+
+// Next returns the next open interval; returns false when iteration is complete
+func (it *Iter) Next() (Interval, bool) {
+	for {
+		for len(it.queue) > 0 {
+			v := it.queue[0]
+			it.queue = it.queue[1:]
+
+			if !v.End.After(it.from) {
+				continue
+			}
+			if v.Start.Before(it.from) {
+				v.Start = it.from
+			}
+
+			switch {
+			case it.pending == nil:
+				it.pending = &v
+			case !v.Start.After(it.pending.End):
+				if v.End.After(it.pending.End) {
+					it.pending.End = v.End
+				}
+			default:
+				next := *it.pending
+				it.pending = &v
+				return next, true
+			}
+		}
+
+		var (
+			done  = it.err != nil || it.idle >= it.horizon || (!it.until.IsZero() && it.date.After(it.until))
+			split = it.pending != nil && it.date.Sub(it.pending.Start) > time.Duration(it.horizon)*24*time.Hour
+		)
+
+		if done || split {
+			if it.pending != nil {
+				next := *it.pending
+				it.from = next.End
+				it.pending = nil
+				return next, true
+			}
+			return Interval{}, false
+		}
+
+		it.load()
+	}
+}
+
+// load queues the intervals of the next date
+func (it *Iter) load() {
+	date := it.date
+	it.date = alignMidnight(date.AddDate(0, 0, 1))
+
+	timeSlots, err := it.r.timeSlots(date)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	if len(timeSlots) == 0 {
+		it.idle++
+		return
+	}
+
+	it.idle = 0
+	for _, timeSlot := range timeSlots {
+		it.queue = append(it.queue, Interval{
+			Start: timeSlot.From.Align(date),
+			End:   timeSlot.To.Align(date),
+		})
+	}
+}
+
+// Intervals returns the open intervals between from and to, clipped to the
+// range provided
+func (s Schedules) Intervals(from, to time.Time) ([]Interval, error) {
+	var (
+		days      = int(to.Sub(from)/(24*time.Hour)) + 2
+		it        = s.Iter(from, WithHorizon(days))
+		intervals []Interval
+	)
+	it.until = to
+
+	for {
+		v, ok := it.Next()
+		if !ok || !v.Start.Before(to) {
+			break
+		}
+		if v.End.After(to) {
+			v.End = to
+		}
+		intervals = append(intervals, v)
+	}
+
+	return intervals, it.Err()
+}
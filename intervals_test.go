@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestSchedules_Intervals(t *testing.T) {
+	var (
+		friday   = time.Date(2020, time.July, 24, 0, 0, 0, 0, time.UTC)
+		at       = func(days int, tm Time) time.Time { return tm.Align(friday.AddDate(0, 0, days)) }
+		weekdays = New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	)
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		From      time.Time
+		To        time.Time
+		Want      []Interval
+	}{
+		"weekend": {
+			Schedules: Schedules{weekdays},
+			From:      at(0, 1200),
+			To:        at(3, 1200),
+			Want: []Interval{
+				{Start: at(0, 1200), End: at(0, 1700)},
+				{Start: at(3, 900), End: at(3, 1200)},
+			},
+		},
+		"overnight": {
+			Schedules: Schedules{New(1800, 200)},
+			From:      at(0, 1200),
+			To:        at(2, 0),
+			Want: []Interval{
+				{Start: at(0, 1800), End: at(1, 200)},
+				{Start: at(1, 1800), End: at(2, 0)},
+			},
+		},
+		"overnight in progress": {
+			Schedules: Schedules{New(1800, 200)},
+			From:      at(1, 100),
+			To:        at(1, 1200),
+			Want: []Interval{
+				{Start: at(1, 100), End: at(1, 200)},
+			},
+		},
+		"adjacent days merged": {
+			Schedules: Schedules{New(2000, 0), New(0, 800)},
+			From:      at(0, 1200),
+			To:        at(1, 1200),
+			Want: []Interval{
+				{Start: at(0, 2000), End: at(1, 800)},
+			},
+		},
+		"exclusions": {
+			Schedules: Schedules{
+				New(900, 1700),
+				ExcludeDateRange("2020-07-25", "2020-07-25"),
+				ExcludeTimeRange("2020-07-26", "2020-07-26", 1200, 1300),
+			},
+			From: at(0, 0),
+			To:   at(3, 0),
+			Want: []Interval{
+				{Start: at(0, 900), End: at(0, 1700)},
+				{Start: at(2, 900), End: at(2, 1200)},
+				{Start: at(2, 1300), End: at(2, 1700)},
+			},
+		},
+		"closed": {
+			Schedules: Schedules{ExcludeDateRange("", "")},
+			From:      at(0, 0),
+			To:        at(3, 0),
+			Want:      nil,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := tc.Schedules.Intervals(tc.From, tc.To)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestSchedules_Iter(t *testing.T) {
+	var (
+		friday   = time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC)
+		weekdays = New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	)
+
+	t.Run("next n", func(t *testing.T) {
+		var (
+			it   = Schedules{weekdays}.Iter(friday)
+			want = []time.Time{
+				friday,
+				time.Date(2020, time.July, 27, 9, 0, 0, 0, time.UTC),
+				time.Date(2020, time.July, 28, 9, 0, 0, 0, time.UTC),
+			}
+		)
+
+		for _, w := range want {
+			got, ok := it.Next()
+			assert.True(t, ok)
+			assert.Equal(t, w, got.Start)
+		}
+		assert.Nil(t, it.Err())
+	})
+
+	t.Run("horizon", func(t *testing.T) {
+		ss := Schedules{weekdays, ExcludeDateRange("2020-07-25", "2020-08-31")}
+
+		it := ss.Iter(friday.AddDate(0, 0, 1))
+		_, ok := it.Next()
+		assert.False(t, ok)
+
+		it = ss.Iter(friday.AddDate(0, 0, 1), WithHorizon(60))
+		got, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2020, time.September, 1, 9, 0, 0, 0, time.UTC), got.Start)
+	})
+
+	t.Run("horizon days", func(t *testing.T) {
+		saturday := friday.AddDate(0, 0, 1)
+
+		it := Schedules{weekdays}.Iter(saturday, WithHorizon(2))
+		_, ok := it.Next()
+		assert.False(t, ok)
+
+		it = Schedules{weekdays}.Iter(saturday, WithHorizon(3))
+		got, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2020, time.July, 27, 9, 0, 0, 0, time.UTC), got.Start)
+	})
+
+	t.Run("location", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		assert.Nil(t, err)
+
+		it := Schedules{weekdays}.In(loc).Iter(time.Date(2020, time.July, 24, 15, 0, 0, 0, time.UTC)) // 11:00 EDT
+		got, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, Interval{
+			Start: time.Date(2020, time.July, 24, 11, 0, 0, 0, loc),
+			End:   time.Date(2020, time.July, 24, 17, 0, 0, 0, loc),
+		}, got)
+	})
+
+	t.Run("always open", func(t *testing.T) {
+		it := Schedules{New(0, 1200), New(1200, 0)}.Iter(friday)
+
+		first, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, friday, first.Start)
+
+		second, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, first.End, second.Start)
+	})
+}
@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// locations caches time.LoadLocation lookups by zone name; unknown zones are
+// cached as nil
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, bool) {
+	if v, ok := locations.Load(name); ok {
+		loc := v.(*time.Location)
+		return loc, loc != nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = nil
+	}
+
+	locations.Store(name, loc)
+	return loc, loc != nil
+}
+
+// In returns a copy of the Schedule evaluated in the provided location.  Times
+// passed to the Schedule will be converted to the wall clock of loc before
+// being evaluated.
+func (s Schedule) In(loc *time.Location) Schedule {
+	fields := bytes.Split(s, []byte(":"))
+	for len(fields) < indexZone {
+		fields = append(fields, nil)
+	}
+	setVersion(fields, 2)
+	fields[indexZone-1] = []byte(loc.String())
+	return bytes.Join(fields, []byte(":"))
+}
+
+// Location returns the location the Schedule is evaluated in.  Returns false
+// if the Schedule has no location or the location is unknown; Parse and
+// TimeSlots return an error for unknown locations
+func (s Schedule) Location() (*time.Location, bool) {
+	i, j, ok := s.index(indexZone)
+	if !ok {
+		return nil, false
+	}
+
+	return loadLocation(string(s[i:j]))
+}
+
+// Zone returns the IANA zone name of the Schedule e.g. America/New_York
+func (s Schedule) Zone() (string, bool) {
+	i, j, ok := s.index(indexZone)
+	if !ok {
+		return "", false
+	}
+
+	return string(s[i:j]), true
+}
+
+// In returns a copy of the Schedules evaluated in the provided location
+func (s Schedules) In(loc *time.Location) Schedules {
+	var ss Schedules
+	for _, v := range s {
+		ss = append(ss, v.In(loc))
+	}
+	return ss
+}
+
+// Location returns the location of the Schedules.  Schedules are expected to
+// share a single location; the first location found is returned
+func (s Schedules) Location() (*time.Location, bool) {
+	for _, v := range s {
+		if loc, ok := v.Location(); ok {
+			return loc, true
+		}
+	}
+	return nil, false
+}
+
+// localize converts t to the wall clock of the Schedules, if any
+func localize(t time.Time, ss ...Schedule) time.Time {
+	if loc, ok := Schedules(ss).Location(); ok {
+		return t.In(loc)
+	}
+	return t
+}
@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestSchedule_In(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	t.Run("include", func(t *testing.T) {
+		s := New(900, 1700, time.Monday).In(ny)
+		assert.Equal(t, "2:::0900:1700:Mo::America/New_York", s.String())
+
+		zone, ok := s.Zone()
+		assert.True(t, ok)
+		assert.Equal(t, "America/New_York", zone)
+
+		loc, ok := s.Location()
+		assert.True(t, ok)
+		assert.Equal(t, ny, loc)
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		s := ExcludeDateRange("2020-07-01", "2020-07-01").In(ny)
+		assert.Equal(t, "2:2020-07-01:2020-07-01:0000:0000::exclude:America/New_York", s.String())
+		assert.True(t, s.IsExclude())
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		s := New(900, 1700).In(ny).In(time.UTC)
+		assert.Equal(t, "2:::0900:1700:::UTC", s.String())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		_, ok := New(900, 1700).Location()
+		assert.False(t, ok)
+	})
+}
+
+func TestSchedules_In(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	ss := Schedules{New(900, 1700, time.Friday)}.In(ny)
+
+	t.Run("contains time", func(t *testing.T) {
+		// Friday 20:00 UTC is Friday 16:00 in New York
+		assert.True(t, ss.ContainsTime(time.Date(2020, time.July, 24, 20, 0, 0, 0, time.UTC)))
+		// Saturday 02:00 UTC is Friday 22:00 in New York
+		assert.False(t, ss.ContainsTime(time.Date(2020, time.July, 25, 2, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("next", func(t *testing.T) {
+		got, err := ss.Next(time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC))
+		assert.Nil(t, err)
+		assert.Equal(t, ny, got.Location())
+		assert.True(t, time.Date(2020, time.July, 24, 13, 0, 0, 0, time.UTC).Equal(got))
+	})
+
+	t.Run("time slots", func(t *testing.T) {
+		// Saturday 02:00 UTC is Friday in New York
+		got, err := ss.TimeSlots(time.Date(2020, time.July, 25, 2, 0, 0, 0, time.UTC))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(900, 1700)}, got)
+	})
+
+	t.Run("unknown zone", func(t *testing.T) {
+		ss := Schedules{Schedule("2:::0900:1700:Fr::America/New_Yrok")}
+		_, ok := ss.Location()
+		assert.False(t, ok)
+
+		_, err := ss.TimeSlots(time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC))
+		assert.EqualError(t, err, "unknown zone, America/New_Yrok")
+
+		_, err = ss.Next(time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC))
+		assert.EqualError(t, err, "unknown zone, America/New_Yrok")
+
+		// matches nothing rather than falling back to the zone of the time
+		assert.False(t, ss[0].Contains(time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC)))
+		assert.False(t, ss.IsOpen(time.Date(2020, time.July, 24, 12, 0, 0, 0, time.UTC)))
+
+		// failed lookups are cached
+		v, ok := locations.Load("America/New_Yrok")
+		assert.True(t, ok)
+		assert.Nil(t, v.(*time.Location))
+	})
+}
@@ -0,0 +1,98 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoAvailability indicates the Schedules had no time available within the
+// search horizon
+var ErrNoAvailability = errors.New("no availability")
+
+// DefaultHorizon is the number of days searched by Next
+const DefaultHorizon = 7
+
+// Option customizes searches such as NextWith
+type Option func(*options)
+
+type options struct {
+	horizon  int
+	duration time.Duration
+	sans     []TimeSlot
+}
+
+func buildOptions(opts ...Option) options {
+	o := options{
+		horizon: DefaultHorizon,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithHorizon sets the number of days to search; defaults to DefaultHorizon
+func WithHorizon(days int) Option {
+	return func(o *options) {
+		o.horizon = days
+	}
+}
+
+// WithDuration requires the time found to have at least duration d of
+// contiguous time available
+func WithDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.duration = d
+	}
+}
+
+// WithSans removes the provided TimeSlots, e.g. existing reservations, from
+// the time available
+func WithSans(sans ...TimeSlot) Option {
+	return func(o *options) {
+		o.sans = append(o.sans, sans...)
+	}
+}
+
+// NextWith returns the next time available from the Schedules provided.  The
+// time returned is in the location of the Schedules, if any.  Returns an error
+// wrapping ErrNoAvailability if no time is available within the horizon.
+func NextWith(date time.Time, ss Schedules, opts ...Option) (time.Time, error) {
+	var (
+		o     = buildOptions(opts...)
+		start = localize(date, ss...)
+	)
+
+	date = start
+	for i := 0; i < o.horizon; i++ {
+		d := date.AddDate(0, 0, i)
+		timeSlots, err := After(d, ss...)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if len(o.sans) > 0 {
+			timeSlots = SubAll(timeSlots, o.sans)
+		}
+
+		for _, timeSlot := range timeSlots {
+			if timeSlot.DurationOn(d) < o.duration {
+				continue
+			}
+
+			next := timeSlot.From.Align(d)
+			if next.Before(start) {
+				// wall clock repeated by a fall back transition
+				next = start
+			}
+			return next, nil
+		}
+
+		if i == 0 {
+			date = alignMidnight(date)
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w in next %v days", ErrNoAvailability, o.horizon)
+}
@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestNextWith(t *testing.T) {
+	var (
+		date    = time.Date(2020, time.December, 20, 8, 0, 0, 0, time.UTC)
+		closure = ExcludeDateRange("2020-12-20", "2021-01-10")
+		ss      = Schedules{New(900, 1000), New(1300, 1700), closure}
+	)
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		Options   []Option
+		Want      time.Time
+		Err       error
+	}{
+		"default horizon": {
+			Schedules: ss,
+			Err:       ErrNoAvailability,
+		},
+		"extended horizon": {
+			Schedules: ss,
+			Options:   []Option{WithHorizon(90)},
+			Want:      time.Date(2021, time.January, 11, 9, 0, 0, 0, time.UTC),
+		},
+		"duration": {
+			Schedules: ss[:2],
+			Options:   []Option{WithDuration(2 * time.Hour)},
+			Want:      time.Date(2020, time.December, 20, 13, 0, 0, 0, time.UTC),
+		},
+		"duration sans": {
+			Schedules: ss[:2],
+			Options: []Option{
+				WithDuration(2 * time.Hour),
+				WithSans(NewTimeSlot(1400, 1500)),
+			},
+			Want: time.Date(2020, time.December, 20, 15, 0, 0, 0, time.UTC),
+		},
+		"duration too long": {
+			Schedules: ss[:2],
+			Options:   []Option{WithDuration(5 * time.Hour)},
+			Err:       ErrNoAvailability,
+		},
+		"duration overnight": {
+			Schedules: Schedules{New(1300, 1700), New(2000, 300)},
+			Options:   []Option{WithDuration(6 * time.Hour)},
+			Want:      time.Date(2020, time.December, 20, 20, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := NextWith(date, tc.Schedules, tc.Options...)
+			if tc.Err != nil {
+				assert.True(t, errors.Is(err, tc.Err))
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestNext_NoAvailability(t *testing.T) {
+	date := time.Date(2020, time.July, 20, 8, 0, 0, 0, time.UTC)
+	_, err := Next(date, Schedules{ExcludeDateRange("", "")})
+	assert.True(t, errors.Is(err, ErrNoAvailability))
+	assert.EqualError(t, err, "no availability in next 7 days")
+}
@@ -0,0 +1,758 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mondayFirst lists the weekdays starting from Monday as in opening_hours and
+// schema.org
+var mondayFirst = []time.Weekday{
+	time.Monday,
+	time.Tuesday,
+	time.Wednesday,
+	time.Thursday,
+	time.Friday,
+	time.Saturday,
+	time.Sunday,
+}
+
+var osmMonths = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// allDay is open from midnight through midnight
+var allDay = TimeSlot{From: Midnight, To: EndOfDay}
+
+// ParseOpeningHours parses the OpenStreetMap opening_hours syntax e.g.
+//
+//	Mo-Fr 08:00-18:00; Sa 09:00-13:00; Dec 24 10:00-14:00; Dec 25 off; PH off
+//
+// Rules may select years, dates, month ranges, nth weekdays e.g. Nov Th[4],
+// weekdays and PH, followed by times and an off modifier.  A later rule for
+// the same weekdays replaces the earlier one while rules separated by a comma
+// add to it.  Rules selecting dates become DateRange and ExcludeDateRange
+// Schedules which take precedence over weekday rules regardless of order.
+//
+// PH rules apply to the dates of the public holidays provided, e.g. the
+// Schedules of the holidays package, and are ignored if none are provided.
+// Unsupported syntax such as sunrise,
+// school holidays and fallback rules is rejected
+func ParseOpeningHours(str string, publicHolidays ...Schedule) (Schedules, error) {
+	tokens, err := osmTokenize(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid opening_hours, %q: %w", str, err)
+	}
+
+	p := &osmParser{tokens: tokens}
+	var rules []osmRule
+	for additional := false; ; {
+		rule, err := p.rule()
+		if err != nil {
+			return nil, fmt.Errorf("invalid opening_hours, %q: %w", str, err)
+		}
+		rule.additional = additional
+		rules = append(rules, rule)
+
+		switch tok := p.next(); tok {
+		case "":
+			ss, err := osmSchedules(rules, publicHolidays)
+			if err != nil {
+				return nil, fmt.Errorf("invalid opening_hours, %q: %w", str, err)
+			}
+			return ss, nil
+		case ";":
+			additional = false
+		case ",":
+			additional = true
+		default:
+			return nil, fmt.Errorf("invalid opening_hours, %q: unexpected %q", str, tok)
+		}
+	}
+}
+
+// osmDate is a date selector; a zero year recurs annually and a zero day
+// selects the whole month
+type osmDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// osmRule is a single parsed rule
+type osmRule struct {
+	dates      [][2]osmDate
+	weekdays   []time.Weekday
+	nth        int // nth weekday of the month e.g. Th[4]
+	offset     int // days the nth weekday is shifted by
+	ph         bool
+	slots      []TimeSlot
+	off        bool
+	additional bool
+}
+
+// osmSchedules converts parsed rules into Schedules
+func osmSchedules(rules []osmRule, publicHolidays []Schedule) (Schedules, error) {
+	var (
+		week  = map[time.Weekday][]TimeSlot{}
+		dated Schedules
+	)
+
+	for _, rule := range rules {
+		slots := rule.slots
+		if !rule.off && len(slots) == 0 {
+			slots = []TimeSlot{allDay}
+		}
+
+		switch {
+		case rule.nth != 0:
+			r := NthWeekday(rule.dates[0][0].month, rule.nth, rule.weekdays[0], rule.offset)
+			if rule.off {
+				dated = append(dated, ExcludeDateRange("", "").Recur(r))
+				break
+			}
+			for _, slot := range slots {
+				dated = append(dated, slotSchedule("", "", slot).Recur(r))
+			}
+
+		case len(rule.dates) > 0:
+			for _, dates := range rule.dates {
+				from, to := dates[0].String(), dates[1].end().String()
+				if rule.off {
+					dated = append(dated, ExcludeDateRange(from, to, rule.weekdays...))
+					continue
+				}
+				for _, slot := range slots {
+					dated = append(dated, slotSchedule(from, to, slot, rule.weekdays...))
+				}
+			}
+
+		default:
+			if rule.ph {
+				for _, h := range publicHolidays {
+					from, _ := h.DateFrom()
+					to, _ := h.DateTo()
+					if rule.off {
+						dated = append(dated, ExcludeDateRange(from, to))
+						continue
+					}
+					for _, slot := range slots {
+						dated = append(dated, slotSchedule(from, to, slot))
+					}
+				}
+				if rule.weekdays == nil {
+					break
+				}
+			}
+
+			weekdays := rule.weekdays
+			if weekdays == nil {
+				weekdays = mondayFirst
+			}
+			for _, w := range weekdays {
+				switch {
+				case rule.off:
+					week[w] = nil
+				case rule.additional:
+					week[w] = append(append([]TimeSlot(nil), week[w]...), slots...)
+				default:
+					week[w] = slots
+				}
+			}
+		}
+	}
+
+	// group weekdays sharing a time slot
+	var (
+		ss    Schedules
+		order []TimeSlot
+		days  = map[TimeSlot][]time.Weekday{}
+	)
+	for _, w := range mondayFirst {
+		for _, slot := range week[w] {
+			if _, ok := days[slot]; !ok {
+				order = append(order, slot)
+			}
+			days[slot] = append(days[slot], w)
+		}
+	}
+	for _, slot := range order {
+		weekdays := days[slot]
+		if len(weekdays) == len(mondayFirst) {
+			weekdays = nil
+		}
+		ss = append(ss, slotSchedule("", "", slot, weekdays...))
+	}
+
+	return append(ss, dated...), nil
+}
+
+// String returns the date as YYYY-MM-DD or --MM-DD if annual
+func (d osmDate) String() string {
+	day := d.day
+	if day == 0 {
+		day = 1
+	}
+	if d.year == 0 {
+		return Annual(d.month, day)
+	}
+	return time.Date(d.year, d.month, day, 0, 0, 0, 0, time.UTC).Format(DateLayout)
+}
+
+// end returns the last date selected by d
+func (d osmDate) end() osmDate {
+	if d.day != 0 {
+		return d
+	}
+	year := d.year
+	if year == 0 {
+		year = 2000 // leap year; annual February ranges include the 29th
+	}
+	d.day = time.Date(year, d.month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return d
+}
+
+// osmTokenize splits opening_hours into words, numbers, times and punctuation
+func osmTokenize(str string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			j := i
+			for j < len(str) && (str[j] >= 'A' && str[j] <= 'Z' || str[j] >= 'a' && str[j] <= 'z') {
+				j++
+			}
+			tokens, i = append(tokens, str[i:j]), j
+
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(str) && str[j] >= '0' && str[j] <= '9' {
+				j++
+			}
+			if j+2 < len(str) && str[j] == ':' && isDigit(str[j+1]) && isDigit(str[j+2]) {
+				j += 3 // hh:mm
+			}
+			tokens, i = append(tokens, str[i:j]), j
+
+		case c == '|':
+			return nil, fmt.Errorf("unsupported fallback rule")
+
+		case strings.IndexByte("-,;/[]+", c) >= 0:
+			tokens, i = append(tokens, string(c)), i+1
+
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// osmParser is a recursive descent parser over opening_hours tokens
+type osmParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *osmParser) peek(n int) string {
+	if i := p.pos + n; i < len(p.tokens) {
+		return p.tokens[i]
+	}
+	return ""
+}
+
+func (p *osmParser) next() string {
+	tok := p.peek(0)
+	if tok != "" {
+		p.pos++
+	}
+	return tok
+}
+
+// rule parses [dates] [weekdays] [times] [off]
+func (p *osmParser) rule() (osmRule, error) {
+	var rule osmRule
+
+	if p.peek(0) == "24" && p.peek(1) == "/" && p.peek(2) == "7" {
+		p.pos += 3
+		rule.slots = []TimeSlot{allDay}
+	} else {
+		if err := p.dates(&rule); err != nil {
+			return osmRule{}, err
+		}
+		if err := p.weekdays(&rule); err != nil {
+			return osmRule{}, err
+		}
+		if err := p.times(&rule); err != nil {
+			return osmRule{}, err
+		}
+	}
+
+	switch strings.ToLower(p.peek(0)) {
+	case "off", "closed":
+		p.pos++
+		rule.off = true
+	case "open":
+		p.pos++
+	}
+
+	if rule.nth != 0 && (len(rule.dates) != 1 || rule.dates[0][0].day != 0 || rule.dates[0][0].year != 0 || rule.dates[0][0] != rule.dates[0][1]) {
+		return osmRule{}, fmt.Errorf("nth weekday requires a single month")
+	}
+	if len(rule.dates) == 0 && len(rule.weekdays) == 0 && !rule.ph && len(rule.slots) == 0 && !rule.off {
+		return osmRule{}, fmt.Errorf("unexpected %q", p.peek(0))
+	}
+
+	return rule, nil
+}
+
+// dates parses a comma separated list of date ranges e.g. 2020 Dec 24-Jan 02
+func (p *osmParser) dates(rule *osmRule) error {
+	for {
+		from, ok, err := p.date(osmDate{})
+		if err != nil || !ok {
+			return err
+		}
+
+		to := from
+		if p.peek(0) == "-" && (osmMonth(p.peek(1)) != 0 || isNumber(p.peek(1))) {
+			p.pos++
+			if to, ok, err = p.date(from); err != nil {
+				return err
+			} else if !ok {
+				return fmt.Errorf("invalid date range")
+			}
+		}
+		if (from.year == 0) != (to.year == 0) {
+			return fmt.Errorf("date range mixes annual and dated dates")
+		}
+		if from.day == 0 && to.day != 0 {
+			return fmt.Errorf("invalid date range")
+		}
+		rule.dates = append(rule.dates, [2]osmDate{from, to})
+
+		if p.peek(0) != "," || (osmMonth(p.peek(1)) == 0 && !isYear(p.peek(1))) {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+// date parses [year] month [day] or, for the end of a range, a day alone
+// within the month of from
+func (p *osmParser) date(from osmDate) (osmDate, bool, error) {
+	var d osmDate
+	if isYear(p.peek(0)) {
+		d.year, _ = strconv.Atoi(p.next())
+	} else {
+		d.year = from.year
+	}
+
+	switch {
+	case osmMonth(p.peek(0)) != 0:
+		d.month = osmMonth(p.next())
+	case from.month != 0 && d.year == from.year && isNumber(p.peek(0)):
+		d.month = from.month
+	case d.year != 0:
+		return osmDate{}, false, fmt.Errorf("year requires a month")
+	default:
+		return osmDate{}, false, nil
+	}
+
+	if tok := p.peek(0); isNumber(tok) && !isYear(tok) {
+		day, _ := strconv.Atoi(p.next())
+		if day < 1 || day > (osmDate{year: d.year, month: d.month}).end().day {
+			return osmDate{}, false, fmt.Errorf("invalid day, %v %v", osmMonths[d.month-1], day)
+		}
+		d.day = day
+	}
+
+	return d, true, nil
+}
+
+// weekdays parses a comma separated list of weekdays, weekday ranges, nth
+// weekdays and PH
+func (p *osmParser) weekdays(rule *osmRule) error {
+	for {
+		switch tok := p.peek(0); {
+		case tok == "PH":
+			p.pos++
+			rule.ph = true
+
+		case osmWeekday(tok) >= 0:
+			p.pos++
+			from := osmWeekday(tok)
+			switch {
+			case p.peek(0) == "-" && osmWeekday(p.peek(1)) >= 0:
+				to := osmWeekday(p.peek(1))
+				p.pos += 2
+				for w := from; ; w = (w + 1) % 7 {
+					rule.weekdays = append(rule.weekdays, w)
+					if w == to {
+						break
+					}
+				}
+
+			case p.peek(0) == "[":
+				if err := p.nth(rule); err != nil {
+					return err
+				}
+				rule.weekdays = append(rule.weekdays, from)
+
+			default:
+				rule.weekdays = append(rule.weekdays, from)
+			}
+
+		default:
+			return nil
+		}
+
+		if p.peek(0) != "," || (osmWeekday(p.peek(1)) < 0 && p.peek(1) != "PH") {
+			break
+		}
+		p.pos++
+	}
+
+	if rule.nth != 0 && (len(rule.weekdays) != 1 || rule.ph) {
+		return fmt.Errorf("nth weekday must be the only weekday")
+	}
+	return nil
+}
+
+// nth parses [n] with an optional +n day offset
+func (p *osmParser) nth(rule *osmRule) error {
+	var (
+		open  = p.next()
+		sign  = 1
+		value string
+	)
+	if p.peek(0) == "-" {
+		p.pos++
+		sign = -1
+	}
+	value = p.next()
+	n, err := strconv.Atoi(value)
+	if open != "[" || p.next() != "]" || err != nil || n < 1 || n > 5 {
+		return fmt.Errorf("invalid nth weekday")
+	}
+	rule.nth = sign * n
+
+	if (p.peek(0) == "+" || p.peek(0) == "-") && isNumber(p.peek(1)) && strings.HasPrefix(p.peek(2), "day") {
+		sign = 1
+		if p.next() == "-" {
+			sign = -1
+		}
+		offset, _ := strconv.Atoi(p.next())
+		p.pos++
+		rule.offset = sign * offset
+	}
+	return nil
+}
+
+// times parses a comma separated list of hh:mm-hh:mm
+func (p *osmParser) times(rule *osmRule) error {
+	for isTime(p.peek(0)) {
+		from, err := osmTime(p.next())
+		if err != nil {
+			return err
+		}
+		if p.next() != "-" || !isTime(p.peek(0)) {
+			return fmt.Errorf("invalid time range")
+		}
+		to, err := osmTime(p.next())
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case from >= EndOfDay:
+			return fmt.Errorf("invalid time, %v", from)
+		case to > EndOfDay && to >= EndOfDay+from:
+			return fmt.Errorf("time range longer than a day, %v-%v", from, to)
+		case to > EndOfDay:
+			to -= EndOfDay // 22:00-26:00 runs overnight
+		case to == from:
+			return fmt.Errorf("empty time range, %v-%v", from, to)
+		}
+		rule.slots = append(rule.slots, NewTimeSlot(from, to))
+
+		if p.peek(0) != "," || !isTime(p.peek(1)) {
+			return nil
+		}
+		p.pos++
+	}
+	return nil
+}
+
+// osmTime parses hh:mm; hours up to 48 run into the following day
+func osmTime(tok string) (Time, error) {
+	i := strings.Index(tok, ":")
+	hour, _ := strconv.Atoi(tok[:i])
+	minute, _ := strconv.Atoi(tok[i+1:])
+	if hour > 48 || minute > 59 || hour == 48 && minute > 0 {
+		return 0, fmt.Errorf("invalid time, %v", tok)
+	}
+	return Time(hour*100 + minute), nil
+}
+
+func isNumber(tok string) bool {
+	return tok != "" && isDigit(tok[0]) && !strings.Contains(tok, ":")
+}
+
+func isYear(tok string) bool {
+	return len(tok) == 4 && isNumber(tok)
+}
+
+func isTime(tok string) bool {
+	return strings.Contains(tok, ":")
+}
+
+// osmMonth returns the month abbreviated by tok or 0
+func osmMonth(tok string) time.Month {
+	for i, month := range osmMonths {
+		if tok == month {
+			return time.Month(i + 1)
+		}
+	}
+	return 0
+}
+
+// osmWeekday returns the weekday abbreviated by tok, e.g. Mo, or -1
+func osmWeekday(tok string) time.Weekday {
+	if len(tok) != 2 {
+		return -1
+	}
+	d, ok := getDayOfTheWeekBytes([]byte(tok))
+	if !ok {
+		return -1
+	}
+	w, _ := d.Weekday()
+	return w
+}
+
+// FormatOpeningHours renders the Schedules in the OpenStreetMap
+// opening_hours syntax; see ParseOpeningHours.  Weekday rules are written
+// first followed by nth weekday rules, date ranges and finally excludes.
+// opening_hours has no notion of a zone so the wall clock times are written
+// as is.  Schedules with every or monthly recurrences and partial day
+// excludes cannot be represented
+func (s Schedules) FormatOpeningHours() (string, error) {
+	var (
+		week     = map[time.Weekday][]TimeSlot{}
+		rules    []string
+		selected = map[string][]TimeSlot{} // time slots by selector
+		order    []string
+		add      = func(selector string, slot TimeSlot) {
+			if _, ok := selected[selector]; !ok {
+				order = append(order, selector)
+			}
+			selected[selector] = append(selected[selector], slot)
+		}
+		excludes []string
+	)
+
+	for _, item := range s {
+		slot, err := item.TimeSlot()
+		if err != nil {
+			return "", err
+		}
+
+		selector, err := osmSelector(item)
+		if err != nil {
+			return "", err
+		}
+
+		_, recurs := item.Recurrence()
+		switch {
+		case item.IsExclude() && !item.ExcludesAllDay():
+			return "", fmt.Errorf("unable to format %v: partial day excludes are not supported", item)
+		case item.IsExclude():
+			excludes = append(excludes, strings.TrimSpace(selector+" off"))
+		case item.HasDateRange() || recurs:
+			add(selector, slot)
+		default:
+			weekdays := item.Weekdays()
+			if len(weekdays) == 0 {
+				weekdays = mondayFirst
+			}
+			for _, w := range weekdays {
+				week[w] = append(week[w], slot)
+			}
+		}
+	}
+
+	// group weekdays with the same hours
+	var (
+		groups []string
+		days   = map[string][]time.Weekday{}
+	)
+	for _, w := range mondayFirst {
+		slots := week[w]
+		if len(slots) == 0 {
+			continue
+		}
+		sort.Slice(slots, func(i, j int) bool { return slots[i].From < slots[j].From })
+		key := osmTimes(slots)
+		if _, ok := days[key]; !ok {
+			groups = append(groups, key)
+		}
+		days[key] = append(days[key], w)
+	}
+	for _, key := range groups {
+		if len(days[key]) == len(mondayFirst) && key == osmTimes([]TimeSlot{allDay}) {
+			rules = append(rules, "24/7")
+			continue
+		}
+		rules = append(rules, osmWeekdays(days[key])+" "+key)
+	}
+
+	for _, selector := range order {
+		rules = append(rules, selector+" "+osmTimes(selected[selector]))
+	}
+
+	return strings.Join(append(rules, excludes...), "; "), nil
+}
+
+// osmSelector returns the date and weekday selectors of the Schedule
+func osmSelector(s Schedule) (string, error) {
+	var parts []string
+
+	if r, ok := s.Recurrence(); ok {
+		rec, err := parseRecurrence(string(r))
+		if err != nil {
+			return "", err
+		}
+		if rec.kind != recurNth || s.HasDateRange() || len(s.Weekdays()) > 0 {
+			return "", fmt.Errorf("unable to format %v: unsupported recurrence", s)
+		}
+
+		d, _ := getDayOfTheWeek(rec.weekday)
+		selector := fmt.Sprintf("%v %v[%v]", osmMonths[rec.month-1], d, rec.n)
+		switch {
+		case rec.offset > 0:
+			selector += fmt.Sprintf(" +%v day", rec.offset)
+		case rec.offset < 0:
+			selector += fmt.Sprintf(" -%v day", -rec.offset)
+		}
+		if rec.offset < -1 || rec.offset > 1 {
+			selector += "s"
+		}
+		return selector, nil
+	}
+
+	if s.HasDateRange() {
+		from, _ := s.DateFrom()
+		to, _ := s.DateTo()
+		v, err := osmDateRange(from, to)
+		if err != nil {
+			return "", fmt.Errorf("unable to format %v: %w", s, err)
+		}
+		parts = append(parts, v)
+	}
+
+	if weekdays := s.Weekdays(); len(weekdays) > 0 && (s.HasDateRange() || s.IsExclude()) {
+		parts = append(parts, osmWeekdays(weekdays))
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// osmDateRange formats a date range e.g. 2020 Dec 24-26 or Dec 24-Jan 02
+func osmDateRange(from, to string) (string, error) {
+	parse := func(v string) (osmDate, error) {
+		annual := isAnnual(v)
+		if annual {
+			v = "2000" + v[1:]
+		}
+		t, err := time.Parse(DateLayout, v)
+		if err != nil {
+			return osmDate{}, err
+		}
+		d := osmDate{year: t.Year(), month: t.Month(), day: t.Day()}
+		if annual {
+			d.year = 0
+		}
+		return d, nil
+	}
+
+	a, err := parse(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := parse(to)
+	if err != nil {
+		return "", err
+	}
+
+	format := func(d osmDate) string {
+		if d.year == 0 {
+			return fmt.Sprintf("%v %02d", osmMonths[d.month-1], d.day)
+		}
+		return fmt.Sprintf("%v %v %02d", d.year, osmMonths[d.month-1], d.day)
+	}
+
+	switch {
+	case a == b:
+		return format(a), nil
+	case a.year == b.year && a.month == b.month:
+		return fmt.Sprintf("%v-%02d", format(a), b.day), nil
+	default:
+		return format(a) + "-" + format(b), nil
+	}
+}
+
+// osmWeekdays formats weekdays, collapsing runs of three or more e.g. Mo-Fr
+func osmWeekdays(weekdays []time.Weekday) string {
+	var (
+		set   = map[time.Weekday]bool{}
+		parts []string
+	)
+	for _, w := range weekdays {
+		set[w] = true
+	}
+
+	for i := 0; i < len(mondayFirst); i++ {
+		if !set[mondayFirst[i]] {
+			continue
+		}
+		j := i
+		for j+1 < len(mondayFirst) && set[mondayFirst[j+1]] {
+			j++
+		}
+
+		from, _ := getDayOfTheWeek(mondayFirst[i])
+		to, _ := getDayOfTheWeek(mondayFirst[j])
+		switch {
+		case j-i >= 2:
+			parts = append(parts, from.String()+"-"+to.String())
+		case j > i:
+			parts = append(parts, from.String(), to.String())
+		default:
+			parts = append(parts, from.String())
+		}
+		i = j
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// osmTimes formats time slots e.g. 08:00-12:00,13:00-17:00
+func osmTimes(slots []TimeSlot) string {
+	var parts []string
+	for _, slot := range slots {
+		to := slot.To
+		if to > EndOfDay {
+			to -= EndOfDay
+		}
+		parts = append(parts, slot.From.String()+"-"+to.String())
+	}
+	return strings.Join(parts, ",")
+}
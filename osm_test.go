@@ -0,0 +1,284 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestParseOpeningHours(t *testing.T) {
+	christmas := ExcludeDateRange("2020-12-25", "2020-12-25")
+
+	testCases := map[string]struct {
+		Input string
+		Want  []string
+	}{
+		"weekdays": {
+			Input: "Mo-Fr 08:00-18:00; Sa 09:00-13:00",
+			Want:  []string{"1:::0800:1800:MoTuWeThFr:", "1:::0900:1300:Sa:"},
+		},
+		"public holidays": {
+			Input: "Mo-Fr 08:00-18:00; PH off",
+			Want:  []string{"1:::0800:1800:MoTuWeThFr:", "1:2020-12-25:2020-12-25:0000:0000::exclude"},
+		},
+		"public holiday hours": {
+			Input: "Mo-Fr 08:00-18:00; PH,Su 10:00-14:00",
+			Want:  []string{"1:::0800:1800:MoTuWeThFr:", "1:::1000:1400:Su:", "1:2020-12-25:2020-12-25:1000:1400::"},
+		},
+		"multiple time ranges": {
+			Input: "Mo-Fr 08:00-12:00,13:00-17:00",
+			Want:  []string{"1:::0800:1200:MoTuWeThFr:", "1:::1300:1700:MoTuWeThFr:"},
+		},
+		"later rule replaces": {
+			Input: "Mo-Fr 08:00-18:00; We 10:00-12:00",
+			Want:  []string{"1:::0800:1800:MoTuThFr:", "1:::1000:1200:We:"},
+		},
+		"additional rule adds": {
+			Input: "Mo-Fr 08:00-12:00, We 14:00-16:00",
+			Want:  []string{"1:::0800:1200:MoTuWeThFr:", "1:::1400:1600:We:"},
+		},
+		"weekday off": {
+			Input: "Mo-Sa 09:00-18:00; We off",
+			Want:  []string{"1:::0900:1800:MoTuThFrSa:"},
+		},
+		"wrapping weekdays": {
+			Input: "Sa-Mo 10:00-12:00",
+			Want:  []string{"1:::1000:1200:MoSaSu:"},
+		},
+		"every day": {
+			Input: "08:00-20:00",
+			Want:  []string{"1:::0800:2000::"},
+		},
+		"24/7": {
+			Input: "24/7",
+			Want:  []string{"1:::0000:2400::"},
+		},
+		"overnight": {
+			Input: "Fr,Sa 22:00-02:00",
+			Want:  []string{"1:::2200:0200:FrSa:"},
+		},
+		"extended hours": {
+			Input: "Fr 22:00-26:00",
+			Want:  []string{"1:::2200:0200:Fr:"},
+		},
+		"until midnight": {
+			Input: "Mo 18:00-24:00",
+			Want:  []string{"1:::1800:2400:Mo:"},
+		},
+		"annual date": {
+			Input: "Mo-Fr 08:00-18:00; Dec 24 10:00-14:00; Dec 25 off",
+			Want: []string{
+				"1:::0800:1800:MoTuWeThFr:",
+				"3:--12-24:--12-24:1000:1400::",
+				"3:--12-25:--12-25:0000:0000::exclude",
+			},
+		},
+		"annual range": {
+			Input: "Dec 24-Jan 02 off",
+			Want:  []string{"3:--12-24:--01-02:0000:0000::exclude"},
+		},
+		"month range": {
+			Input: "Jan-Feb Sa,Su 10:00-16:00",
+			Want:  []string{"3:--01-01:--02-29:1000:1600:SaSu:"},
+		},
+		"dated range": {
+			Input: "2020 Dec 24-2021 Jan 02 off",
+			Want:  []string{"1:2020-12-24:2021-01-02:0000:0000::exclude"},
+		},
+		"dated days": {
+			Input: "2020 Dec 24-26 10:00-14:00",
+			Want:  []string{"1:2020-12-24:2020-12-26:1000:1400::"},
+		},
+		"date list": {
+			Input: "Dec 24,Dec 31 10:00-14:00",
+			Want:  []string{"3:--12-24:--12-24:1000:1400::", "3:--12-31:--12-31:1000:1400::"},
+		},
+		"all day date": {
+			Input: "2020 Dec 31",
+			Want:  []string{"1:2020-12-31:2020-12-31:0000:2400::"},
+		},
+		"nth weekday": {
+			Input: "Nov Th[4] off; Nov Th[4] +1 day 10:00-14:00; May Mo[-1] off",
+			Want: []string{
+				"4:::0000:0000::exclude::nth=11/4Th",
+				"4:::1000:1400::::nth=11/4Th+1",
+				"4:::0000:0000::exclude::nth=5/-1Mo",
+			},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			ss, err := ParseOpeningHours(tc.Input, christmas)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, ss.StringSlice())
+
+			for _, s := range ss {
+				_, err := Parse(s.String())
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestParseOpeningHours_withoutPublicHolidays(t *testing.T) {
+	testCases := map[string]struct {
+		Input string
+		Want  []string
+	}{
+		"off": {
+			Input: "Mo-Fr 08:00-18:00; Sa 09:00-13:00; PH off",
+			Want:  []string{"1:::0800:1800:MoTuWeThFr:", "1:::0900:1300:Sa:"},
+		},
+		"hours": {
+			Input: "Mo-Fr 08:00-18:00; PH,Su 10:00-14:00",
+			Want:  []string{"1:::0800:1800:MoTuWeThFr:", "1:::1000:1400:Su:"},
+		},
+		"only": {
+			Input: "PH off",
+			Want:  nil,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			ss, err := ParseOpeningHours(tc.Input)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, ss.StringSlice())
+		})
+	}
+}
+
+func TestParseOpeningHours_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		Input string
+		Want  string
+	}{
+		"open end": {
+			Input: "Mo 10:00+",
+			Want:  `invalid opening_hours, "Mo 10:00+": invalid time range`,
+		},
+		"sunrise": {
+			Input: "sunrise-sunset",
+			Want:  `invalid opening_hours, "sunrise-sunset": unexpected "sunrise"`,
+		},
+		"fallback": {
+			Input: "Mo-Fr 08:00-18:00 || \"by appointment\"",
+			Want:  `invalid opening_hours, "Mo-Fr 08:00-18:00 || \"by appointment\"": unsupported fallback rule`,
+		},
+		"invalid day": {
+			Input: "Feb 30 off",
+			Want:  `invalid opening_hours, "Feb 30 off": invalid day, Feb 30`,
+		},
+		"invalid time": {
+			Input: "Mo 08:00-48:30",
+			Want:  `invalid opening_hours, "Mo 08:00-48:30": invalid time, 48:30`,
+		},
+		"longer than a day": {
+			Input: "Mo 08:00-34:00",
+			Want:  `invalid opening_hours, "Mo 08:00-34:00": time range longer than a day, 08:00-34:00`,
+		},
+		"a day": {
+			Input: "Mo 08:00-32:00",
+			Want:  `invalid opening_hours, "Mo 08:00-32:00": time range longer than a day, 08:00-32:00`,
+		},
+		"nth weekday range": {
+			Input: "Nov Th[4]-Fr off",
+			Want:  `invalid opening_hours, "Nov Th[4]-Fr off": unexpected "-"`,
+		},
+		"mixed date range": {
+			Input: "Dec 24-2021 Jan 02 off",
+			Want:  `invalid opening_hours, "Dec 24-2021 Jan 02 off": date range mixes annual and dated dates`,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			_, err := ParseOpeningHours(tc.Input)
+			assert.EqualError(t, err, tc.Want)
+		})
+	}
+}
+
+func TestSchedules_FormatOpeningHours(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		Want      string
+	}{
+		"weekdays": {
+			Schedules: Schedules{New(800, 1800, weekdays...), New(900, 1300, time.Saturday)},
+			Want:      "Mo-Fr 08:00-18:00; Sa 09:00-13:00",
+		},
+		"grouped": {
+			Schedules: Schedules{New(800, 1200, weekdays...), New(1300, 1700, weekdays...), New(1000, 1200, time.Saturday, time.Sunday)},
+			Want:      "Mo-Fr 08:00-12:00,13:00-17:00; Sa,Su 10:00-12:00",
+		},
+		"split weekdays": {
+			Schedules: Schedules{New(800, 1800, time.Monday, time.Wednesday, time.Thursday)},
+			Want:      "Mo,We,Th 08:00-18:00",
+		},
+		"every day": {
+			Schedules: Schedules{New(800, 2000)},
+			Want:      "Mo-Su 08:00-20:00",
+		},
+		"24/7": {
+			Schedules: Schedules{New(Midnight, EndOfDay)},
+			Want:      "24/7",
+		},
+		"overnight": {
+			Schedules: Schedules{New(2200, 200, time.Friday, time.Saturday)},
+			Want:      "Fr,Sa 22:00-02:00",
+		},
+		"dates": {
+			Schedules: Schedules{
+				New(800, 1800, weekdays...),
+				DateRange(Annual(time.December, 24), Annual(time.December, 24), 1000, 1400),
+				ExcludeDateRange(Annual(time.December, 25), Annual(time.December, 26)),
+				ExcludeDateRange("2020-12-31", "2021-01-01"),
+				DateRange("2021-01-02", "2021-01-02", 1000, 1200, time.Saturday),
+			},
+			Want: "Mo-Fr 08:00-18:00; Dec 24 10:00-14:00; 2021 Jan 02 Sa 10:00-12:00; Dec 25-26 off; 2020 Dec 31-2021 Jan 01 off",
+		},
+		"nth weekday": {
+			Schedules: Schedules{
+				ExcludeDateRange("", "").Recur(NthWeekday(time.November, 4, time.Thursday, 0)),
+				New(1000, 1400).Recur(NthWeekday(time.November, 4, time.Thursday, 1)),
+			},
+			Want: "Nov Th[4] +1 day 10:00-14:00; Nov Th[4] off",
+		},
+		"weekday exclude": {
+			Schedules: Schedules{New(900, 1700), ExcludeDateRange("", "", time.Sunday)},
+			Want:      "Mo-Su 09:00-17:00; Su off",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := tc.Schedules.FormatOpeningHours()
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got)
+
+			// formatted hours parse back to the same hours
+			ss, err := ParseOpeningHours(got)
+			assert.Nil(t, err)
+			for date := time.Date(2020, 11, 1, 0, 0, 0, 0, time.UTC); date.Year() == 2020; date = date.AddDate(0, 0, 1) {
+				want, err := tc.Schedules.TimeSlots(date)
+				assert.Nil(t, err)
+				actual, err := ss.TimeSlots(date)
+				assert.Nil(t, err)
+				assert.Equal(t, want, actual, date.Format(DateLayout))
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := Schedules{New(900, 1700).Recur(EveryWeeks(2, "2020-01-04"))}.FormatOpeningHours()
+		assert.EqualError(t, err, "unable to format 4:::0900:1700::::every=2w/2020-01-04: unsupported recurrence")
+
+		_, err = Schedules{ExcludeTimeRange("2020-01-06", "2020-01-06", 1200, 1300)}.FormatOpeningHours()
+		assert.EqualError(t, err, "unable to format 1:2020-01-06:2020-01-06:1200:1300::exclude: partial day excludes are not supported")
+	})
+}
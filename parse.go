@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldNames names each field of the Schedule encoding by index
+var fieldNames = map[int]string{
+	indexVersion:    "version",
+	indexDateFrom:   "date-from",
+	indexDateTo:     "date-to",
+	indexFrom:       "from",
+	indexTo:         "to",
+	indexWeekdays:   "weekdays",
+	indexExclude:    "exclude",
+	indexZone:       "zone",
+	indexRecurrence: "recurrence",
+}
+
+// versionFields holds the number of fields supported by each version of the
+// Schedule encoding.  New versions may only append fields.
+var versionFields = map[int]int{
+	1: indexExclude,
+	2: indexZone,       // adds zone
+	3: indexZone,       // adds annual dates
+	4: indexRecurrence, // adds recurrence
+}
+
+// parseVersion returns the version of the Schedule encoding; versions are
+// compared as integers
+func parseVersion(v string) (int, bool) {
+	n, err := strconv.Atoi(v)
+	if err != nil || strconv.Itoa(n) != v {
+		return 0, false
+	}
+	return n, true
+}
+
+// setVersion raises the version of the Schedule fields to at least v
+func setVersion(fields [][]byte, v int) {
+	if n, _ := parseVersion(string(fields[indexVersion-1])); n < v {
+		fields[indexVersion-1] = []byte(strconv.Itoa(v))
+	}
+}
+
+// ParseError describes a Schedule that could not be parsed
+type ParseError struct {
+	Input  string // Input that was being parsed
+	Field  int    // Field is the 1 based index of the invalid field
+	Reason string // Reason the field was rejected
+}
+
+// Error implements error
+func (e *ParseError) Error() string {
+	name, ok := fieldNames[e.Field]
+	if !ok {
+		name = fmt.Sprintf("field %v", e.Field)
+	}
+	return fmt.Sprintf("invalid Schedule, %q: %v: %v", e.Input, name, e.Reason)
+}
+
+// Parse parses and validates a Schedule from its string encoding.  Every
+// version of the encoding is accepted; trailing empty fields may be omitted.
+func Parse(str string) (Schedule, error) {
+	var (
+		fields = strings.Split(str, ":")
+		fail   = func(field int, format string, args ...interface{}) (Schedule, error) {
+			return nil, &ParseError{
+				Input:  str,
+				Field:  field,
+				Reason: fmt.Sprintf(format, args...),
+			}
+		}
+		field = func(n int) string {
+			if n > len(fields) {
+				return ""
+			}
+			return fields[n-1]
+		}
+	)
+
+	version, ok := parseVersion(field(indexVersion))
+	n := versionFields[version]
+	switch {
+	case field(indexVersion) == "":
+		return fail(indexVersion, "missing version")
+	case !ok || n == 0:
+		return fail(indexVersion, "unsupported version, %v", field(indexVersion))
+	case len(fields) > n:
+		return fail(n+1, "unexpected field for version %v", version)
+	}
+
+	dateFrom, dateTo := field(indexDateFrom), field(indexDateTo)
+	switch {
+	case dateFrom == "" && dateTo != "":
+		return fail(indexDateFrom, "missing date-from")
+	case dateFrom != "" && dateTo == "":
+		return fail(indexDateTo, "missing date-to")
+	}
+	for _, i := range []int{indexDateFrom, indexDateTo} {
+		if v := field(i); v != "" {
+			if reason := validateDate(v); reason != "" {
+				return fail(i, reason)
+			}
+		}
+	}
+	switch {
+	case isAnnual(dateFrom) != isAnnual(dateTo):
+		return fail(indexDateTo, "date-from and date-to must both be annual")
+	case isAnnual(dateFrom) && version < 3:
+		return fail(indexDateFrom, "annual dates require version 3")
+	case !isAnnual(dateFrom) && dateFrom > dateTo:
+		return fail(indexDateTo, "date-to before date-from")
+	}
+
+	for _, i := range []int{indexFrom, indexTo} {
+		if i == indexTo && field(i) == "2400" {
+			continue // EndOfDay; open through midnight
+		}
+		if reason := validateTime(field(i)); reason != "" {
+			return fail(i, reason)
+		}
+	}
+
+	weekdays := field(indexWeekdays)
+	if len(weekdays)%2 != 0 {
+		return fail(indexWeekdays, "invalid weekdays, %v", weekdays)
+	}
+	for i := 0; i < len(weekdays); i += 2 {
+		if _, ok := getDayOfTheWeekBytes([]byte(weekdays[i : i+2])); !ok {
+			return fail(indexWeekdays, "invalid weekday, %v", weekdays[i:i+2])
+		}
+	}
+
+	if v := field(indexExclude); v != "" && v != exclude {
+		return fail(indexExclude, "invalid exclude flag, %v", v)
+	}
+
+	if v := field(indexZone); v != "" {
+		if _, ok := loadLocation(v); !ok {
+			return fail(indexZone, "unknown zone, %v", v)
+		}
+	}
+
+	if v := field(indexRecurrence); v != "" {
+		if _, err := parseRecurrence(v); err != nil {
+			return fail(indexRecurrence, "%v", err)
+		}
+	}
+
+	return Schedule(str), nil
+}
+
+// validateDate returns the reason v is not a valid YYYY-MM-DD or annual --MM-DD
+// date or "" if valid
+func validateDate(v string) string {
+	if isAnnual(v) {
+		// a leap year allows --02-29
+		if _, err := time.Parse(DateLayout, "2000"+v[1:]); err != nil || len(v) != len("--01-02") {
+			return fmt.Sprintf("invalid annual date, %v", v)
+		}
+		return ""
+	}
+	if _, err := time.Parse(DateLayout, v); err != nil {
+		return fmt.Sprintf("invalid date, %v", v)
+	}
+	return ""
+}
+
+// validateTime returns the reason v is not a valid hhmm time or "" if valid
+func validateTime(v string) string {
+	if v == "" {
+		return "missing time"
+	}
+	if len(v) != 4 {
+		return fmt.Sprintf("invalid time, %v", v)
+	}
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return fmt.Sprintf("invalid time, %v", v)
+		}
+	}
+	if hour := int(v[0]-'0')*10 + int(v[1]-'0'); hour > 23 {
+		return fmt.Sprintf("invalid hour, %v", hour)
+	}
+	if minute := int(v[2]-'0')*10 + int(v[3]-'0'); minute > 59 {
+		return fmt.Sprintf("invalid minute, %v", minute)
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := map[string]struct {
+		Input  string
+		Field  int
+		Reason string
+	}{
+		"new": {
+			Input: New(800, 1800, time.Monday, time.Tuesday).String(),
+		},
+		"date range": {
+			Input: DateRange("2020-12-24", "2020-12-24", 800, 1200).String(),
+		},
+		"exclude": {
+			Input: ExcludeDateRange("2020-12-25", "2020-12-26").String(),
+		},
+		"overnight": {
+			Input: New(1800, 200, time.Friday).String(),
+		},
+		"zone": {
+			Input: "2:::0900:1700:Mo::America/New_York",
+		},
+		"end of day": {
+			Input: New(0, EndOfDay).String(),
+		},
+		"end of day from": {
+			Input:  "1:::2400:2400::",
+			Field:  indexFrom,
+			Reason: "invalid hour, 24",
+		},
+		"trailing fields omitted": {
+			Input: "1:::0900:1700",
+		},
+		"garbage": {
+			Input:  "1:x:y:abc:def:Zz:",
+			Field:  indexDateFrom,
+			Reason: "invalid date, x",
+		},
+		"missing version": {
+			Input:  ":::0900:1700::",
+			Field:  indexVersion,
+			Reason: "missing version",
+		},
+		"unsupported version": {
+			Input:  "9:::0900:1700::",
+			Field:  indexVersion,
+			Reason: "unsupported version, 9",
+		},
+		"two digit version": {
+			Input:  "10:--12-24:--12-24:0900:1700::",
+			Field:  indexVersion,
+			Reason: "unsupported version, 10",
+		},
+		"leading zero version": {
+			Input:  "01:::0900:1700::",
+			Field:  indexVersion,
+			Reason: "unsupported version, 01",
+		},
+		"too many fields": {
+			Input:  "1:::0900:1700:::America/New_York",
+			Field:  indexZone,
+			Reason: "unexpected field for version 1",
+		},
+		"recurrence before version 4": {
+			Input:  "2:::0900:1700::::every=2w/2020-01-04",
+			Field:  indexRecurrence,
+			Reason: "unexpected field for version 2",
+		},
+		"annual before version 3": {
+			Input:  "2:--12-24:--12-24:0900:1700::",
+			Field:  indexDateFrom,
+			Reason: "annual dates require version 3",
+		},
+		"annual zone": {
+			Input: DateRange(Annual(time.December, 24), Annual(time.December, 24), 900, 1700).In(time.UTC).String(),
+		},
+		"annual recurrence": {
+			Input: DateRange(Annual(time.December, 1), Annual(time.December, 31), 900, 1700).Recur(DaysOfMonth(1)).In(time.UTC).String(),
+		},
+		"missing date-to": {
+			Input:  "1:2020-01-01::0900:1700::",
+			Field:  indexDateTo,
+			Reason: "missing date-to",
+		},
+		"missing date-from": {
+			Input:  "1::2020-01-01:0900:1700::",
+			Field:  indexDateFrom,
+			Reason: "missing date-from",
+		},
+		"invalid date": {
+			Input:  "1:2020-02-30:2020-03-01:0900:1700::",
+			Field:  indexDateFrom,
+			Reason: "invalid date, 2020-02-30",
+		},
+		"reversed dates": {
+			Input:  "1:2020-03-01:2020-02-01:0900:1700::",
+			Field:  indexDateTo,
+			Reason: "date-to before date-from",
+		},
+		"missing from": {
+			Input:  "1::::1700::",
+			Field:  indexFrom,
+			Reason: "missing time",
+		},
+		"missing to": {
+			Input:  "1:::0900",
+			Field:  indexTo,
+			Reason: "missing time",
+		},
+		"invalid hour": {
+			Input:  "1:::2400:1700::",
+			Field:  indexFrom,
+			Reason: "invalid hour, 24",
+		},
+		"invalid minute": {
+			Input:  "1:::0900:1760::",
+			Field:  indexTo,
+			Reason: "invalid minute, 60",
+		},
+		"invalid time": {
+			Input:  "1:::900:1700::",
+			Field:  indexFrom,
+			Reason: "invalid time, 900",
+		},
+		"invalid weekday": {
+			Input:  "1:::0900:1700:MoZz:",
+			Field:  indexWeekdays,
+			Reason: "invalid weekday, Zz",
+		},
+		"odd weekdays": {
+			Input:  "1:::0900:1700:MoT:",
+			Field:  indexWeekdays,
+			Reason: "invalid weekdays, MoT",
+		},
+		"invalid exclude": {
+			Input:  "1:::0900:1700::include",
+			Field:  indexExclude,
+			Reason: "invalid exclude flag, include",
+		},
+		"unknown zone": {
+			Input:  "2:::0900:1700:::Mars/Olympus_Mons",
+			Field:  indexZone,
+			Reason: "unknown zone, Mars/Olympus_Mons",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := Parse(tc.Input)
+			if tc.Reason == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Input, got.String())
+				return
+			}
+
+			var pe *ParseError
+			assert.True(t, errors.As(err, &pe))
+			assert.Equal(t, tc.Input, pe.Input)
+			assert.Equal(t, tc.Field, pe.Field)
+			assert.Equal(t, tc.Reason, pe.Reason)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestParseError_Error(t *testing.T) {
+	_, err := Parse("1:::0900:1760::")
+	assert.EqualError(t, err, `invalid Schedule, "1:::0900:1760::": to: invalid minute, 60`)
+}
+
+func TestSetVersion(t *testing.T) {
+	testCases := map[string]struct {
+		Version string
+		Min     int
+		Want    string
+	}{
+		"raise": {
+			Version: "1",
+			Min:     4,
+			Want:    "4",
+		},
+		"keep": {
+			Version: "4",
+			Min:     2,
+			Want:    "4",
+		},
+		"two digits": {
+			Version: "10",
+			Min:     3,
+			Want:    "10",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			fields := [][]byte{[]byte(tc.Version)}
+			setVersion(fields, tc.Min)
+			assert.Equal(t, tc.Want, string(fields[0]))
+		})
+	}
+}
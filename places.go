@@ -0,0 +1,444 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlacesHours holds the hours of a location in the Google Business Profile
+// format, regularHours.periods and specialHours.specialHourPeriods
+type PlacesHours struct {
+	RegularHours *PlacesRegularHours `json:"regularHours,omitempty"`
+	SpecialHours *PlacesSpecialHours `json:"specialHours,omitempty"`
+}
+
+// PlacesRegularHours lists the weekly periods a location is open
+type PlacesRegularHours struct {
+	Periods []PlacesPeriod `json:"periods"`
+}
+
+// PlacesPeriod is open from OpenDay at OpenTime through CloseDay at CloseTime.
+// Days are written in upper case e.g. MONDAY
+type PlacesPeriod struct {
+	OpenDay   string     `json:"openDay"`
+	OpenTime  PlacesTime `json:"openTime"`
+	CloseDay  string     `json:"closeDay"`
+	CloseTime PlacesTime `json:"closeTime"`
+}
+
+// PlacesSpecialHours lists the dates whose hours differ from regular hours
+type PlacesSpecialHours struct {
+	SpecialHourPeriods []PlacesSpecialPeriod `json:"specialHourPeriods"`
+}
+
+// PlacesSpecialPeriod replaces the regular hours of StartDate.  EndDate, if
+// present, is the day after StartDate for hours closing after midnight
+type PlacesSpecialPeriod struct {
+	StartDate PlacesDate  `json:"startDate"`
+	OpenTime  *PlacesTime `json:"openTime,omitempty"`
+	EndDate   *PlacesDate `json:"endDate,omitempty"`
+	CloseTime *PlacesTime `json:"closeTime,omitempty"`
+	Closed    bool        `json:"closed,omitempty"`
+}
+
+// PlacesTime is a time of day; 24:00 closes at the end of the day
+type PlacesTime struct {
+	Hours   int `json:"hours,omitempty"`
+	Minutes int `json:"minutes,omitempty"`
+}
+
+// PlacesDate is a calendar date; a zero Year recurs annually
+type PlacesDate struct {
+	Year  int `json:"year,omitempty"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// FromPlacesHours converts PlacesHours to Schedules.  Regular periods with the
+// same hours are grouped by weekday.  Special hours become DateRange overrides
+// and closed special hours become ExcludeDateRange, merging consecutive dates
+// with the same hours into a single range
+func FromPlacesHours(h PlacesHours) (Schedules, error) {
+	var ss Schedules
+	if h.RegularHours != nil {
+		regular, err := placesRegular(h.RegularHours.Periods)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, regular...)
+	}
+	if h.SpecialHours != nil {
+		special, err := placesSpecial(h.SpecialHours.SpecialHourPeriods)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, special...)
+	}
+	return ss, nil
+}
+
+func placesRegular(periods []PlacesPeriod) (Schedules, error) {
+	var (
+		order []TimeSlot
+		days  = map[TimeSlot][]time.Weekday{}
+	)
+	for i, p := range periods {
+		weekday, slot, err := p.timeSlot()
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular period %v: %w", i, err)
+		}
+		if _, ok := days[slot]; !ok {
+			order = append(order, slot)
+		}
+		days[slot] = append(days[slot], weekday)
+	}
+
+	var ss Schedules
+	for _, slot := range order {
+		var weekdays []time.Weekday
+		for _, w := range mondayFirst {
+			for _, v := range days[slot] {
+				if v == w {
+					weekdays = append(weekdays, w)
+					break
+				}
+			}
+		}
+		if len(weekdays) == len(mondayFirst) {
+			weekdays = nil
+		}
+		ss = append(ss, slotSchedule("", "", slot, weekdays...))
+	}
+	return ss, nil
+}
+
+// timeSlot returns the weekday and TimeSlot of the period
+func (p PlacesPeriod) timeSlot() (time.Weekday, TimeSlot, error) {
+	openDay, ok := placesWeekday(p.OpenDay)
+	if !ok {
+		return 0, TimeSlot{}, fmt.Errorf("invalid openDay, %v", p.OpenDay)
+	}
+	closeDay, ok := placesWeekday(p.CloseDay)
+	if !ok {
+		return 0, TimeSlot{}, fmt.Errorf("invalid closeDay, %v", p.CloseDay)
+	}
+	slot, err := placesSlot(&p.OpenTime, &p.CloseTime, int(closeDay-openDay+7)%7)
+	if err != nil {
+		return 0, TimeSlot{}, err
+	}
+	return openDay, slot, nil
+}
+
+// placesDay is a single special hours date
+type placesDay struct {
+	from, to time.Time // first and last dates
+	annual   bool
+	slot     TimeSlot
+	closed   bool
+}
+
+func placesSpecial(periods []PlacesSpecialPeriod) (Schedules, error) {
+	var days []placesDay
+	for i, p := range periods {
+		day, err := p.day()
+		if err != nil {
+			return nil, fmt.Errorf("invalid special period %v: %w", i, err)
+		}
+
+		if n := len(days); n > 0 {
+			prev := &days[n-1]
+			next := prev.to.AddDate(0, 0, 1)
+			if prev.annual == day.annual && prev.slot == day.slot && prev.closed == day.closed &&
+				next.Month() == day.from.Month() && next.Day() == day.from.Day() &&
+				(day.annual || next.Year() == day.from.Year()) {
+				prev.to = next
+				continue
+			}
+		}
+		days = append(days, day)
+	}
+
+	var ss Schedules
+	for _, day := range days {
+		from, to := placesDateString(day.from, day.annual), placesDateString(day.to, day.annual)
+		if day.closed {
+			ss = append(ss, ExcludeDateRange(from, to))
+			continue
+		}
+		ss = append(ss, slotSchedule(from, to, day.slot))
+	}
+	return ss, nil
+}
+
+// day returns the date and hours of the special period
+func (p PlacesSpecialPeriod) day() (placesDay, error) {
+	start, annual, err := p.StartDate.time()
+	if err != nil {
+		return placesDay{}, err
+	}
+	day := placesDay{from: start, to: start, annual: annual}
+	if p.Closed {
+		day.closed = true
+		return day, nil
+	}
+
+	days := 0
+	if p.EndDate != nil {
+		end, endAnnual, err := p.EndDate.time()
+		if err != nil {
+			return placesDay{}, err
+		}
+		if annual != endAnnual {
+			return placesDay{}, fmt.Errorf("startDate and endDate must both include a year")
+		}
+		if annual && end.Before(start) {
+			end = end.AddDate(1, 0, 0) // wraps the year boundary
+		}
+		days = int(end.Sub(start).Hours() / 24)
+	}
+
+	opens, closes := p.OpenTime, p.CloseTime
+	if opens == nil {
+		opens = &PlacesTime{}
+	}
+	if closes == nil {
+		closes = &PlacesTime{}
+	}
+	day.slot, err = placesSlot(opens, closes, days)
+	if err != nil {
+		return placesDay{}, err
+	}
+	return day, nil
+}
+
+// placesSlot returns the TimeSlot opening at opens and closing at closes the
+// given number of days later
+func placesSlot(opens, closes *PlacesTime, days int) (TimeSlot, error) {
+	from, err := opens.time()
+	if err != nil {
+		return TimeSlot{}, err
+	}
+	to, err := closes.time()
+	if err != nil {
+		return TimeSlot{}, err
+	}
+
+	switch {
+	case from == EndOfDay:
+		return TimeSlot{}, fmt.Errorf("invalid open time, %v", from)
+	case days == 0 && to > from:
+		return TimeSlot{From: from, To: to}, nil
+	case days == 1 && (to < from || to == Midnight):
+		return TimeSlot{From: from, To: to + EndOfDay}, nil
+	default:
+		return TimeSlot{}, fmt.Errorf("hours must close within a day of opening, %v-%v", from, to)
+	}
+}
+
+// time returns the Time; 24:00 is EndOfDay
+func (t PlacesTime) time() (Time, error) {
+	switch {
+	case t.Hours == 24 && t.Minutes == 0:
+		return EndOfDay, nil
+	case t.Hours < 0 || t.Hours > 23 || t.Minutes < 0 || t.Minutes > 59:
+		return 0, fmt.Errorf("invalid time, %02d:%02d", t.Hours, t.Minutes)
+	default:
+		return NewTime(t.Hours, t.Minutes), nil
+	}
+}
+
+// time returns the date at midnight UTC; annual dates are returned in the
+// leap year 2000
+func (d PlacesDate) time() (time.Time, bool, error) {
+	year := d.Year
+	if year == 0 {
+		year = 2000
+	}
+	t := time.Date(year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC)
+	if d.Month < 1 || d.Month > 12 || t.Day() != d.Day {
+		return time.Time{}, false, fmt.Errorf("invalid date, %04d-%02d-%02d", d.Year, d.Month, d.Day)
+	}
+	return t, d.Year == 0, nil
+}
+
+func placesDateString(t time.Time, annual bool) string {
+	if annual {
+		return Annual(t.Month(), t.Day())
+	}
+	return t.Format(DateLayout)
+}
+
+// placesWeekday parses an upper case day e.g. MONDAY
+func placesWeekday(day string) (time.Weekday, bool) {
+	for _, w := range mondayFirst {
+		if strings.EqualFold(day, w.String()) {
+			return w, true
+		}
+	}
+	return 0, false
+}
+
+// PlacesHours converts the Schedules to PlacesHours; see FromPlacesHours.
+// Date ranges are written as a special period for each date.  Recurrences,
+// partial day excludes and excludes without a date range cannot be
+// represented
+func (s Schedules) PlacesHours() (PlacesHours, error) {
+	var (
+		periods []PlacesPeriod
+		special []PlacesSpecialPeriod
+	)
+	for _, item := range s {
+		var err error
+		if item.HasDateRange() {
+			special, err = placesAppendSpecial(special, item)
+		} else {
+			periods, err = placesAppendPeriods(periods, item)
+		}
+		if err != nil {
+			return PlacesHours{}, fmt.Errorf("unable to encode %v as places hours: %w", item, err)
+		}
+	}
+
+	index := map[string]int{}
+	for i, w := range mondayFirst {
+		index[strings.ToUpper(w.String())] = i
+	}
+	sort.SliceStable(periods, func(i, j int) bool {
+		a, b := periods[i], periods[j]
+		if a.OpenDay != b.OpenDay {
+			return index[a.OpenDay] < index[b.OpenDay]
+		}
+		if a.OpenTime.Hours != b.OpenTime.Hours {
+			return a.OpenTime.Hours < b.OpenTime.Hours
+		}
+		return a.OpenTime.Minutes < b.OpenTime.Minutes
+	})
+
+	var h PlacesHours
+	if len(periods) > 0 {
+		h.RegularHours = &PlacesRegularHours{Periods: periods}
+	}
+	if len(special) > 0 {
+		h.SpecialHours = &PlacesSpecialHours{SpecialHourPeriods: special}
+	}
+	return h, nil
+}
+
+func placesAppendPeriods(periods []PlacesPeriod, s Schedule) ([]PlacesPeriod, error) {
+	if _, ok := s.Recurrence(); ok {
+		return nil, fmt.Errorf("recurrences are not supported")
+	}
+	if s.IsExclude() {
+		return nil, fmt.Errorf("excludes require a date range")
+	}
+
+	slot, err := s.TimeSlot()
+	if err != nil {
+		return nil, err
+	}
+
+	weekdays := s.Weekdays()
+	if len(weekdays) == 0 {
+		weekdays = mondayFirst
+	}
+	for _, w := range weekdays {
+		closeDay, closeTime := w, slot.To
+		if slot.To > EndOfDay {
+			closeDay, closeTime = (w+1)%7, slot.To-EndOfDay
+		}
+		periods = append(periods, PlacesPeriod{
+			OpenDay:   strings.ToUpper(w.String()),
+			OpenTime:  placesTime(slot.From),
+			CloseDay:  strings.ToUpper(closeDay.String()),
+			CloseTime: placesTime(closeTime),
+		})
+	}
+	return periods, nil
+}
+
+func placesAppendSpecial(special []PlacesSpecialPeriod, s Schedule) ([]PlacesSpecialPeriod, error) {
+	if _, ok := s.Recurrence(); ok {
+		return nil, fmt.Errorf("recurrences are not supported")
+	}
+	if s.IsExclude() && !s.ExcludesAllDay() {
+		return nil, fmt.Errorf("partial day excludes are not supported")
+	}
+
+	slot, err := s.TimeSlot()
+	if err != nil {
+		return nil, err
+	}
+
+	from, _ := s.DateFrom()
+	to, _ := s.DateTo()
+	annual := isAnnual(from)
+	if to == maxDate {
+		return nil, fmt.Errorf("open ended date ranges are not supported")
+	}
+	if annual && len(s.Weekdays()) > 0 {
+		return nil, fmt.Errorf("annual dates with weekdays are not supported")
+	}
+
+	first, last, err := placesDates(from, to)
+	if err != nil {
+		return nil, err
+	}
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		if !s.ContainsWeekday(date.Weekday()) {
+			continue
+		}
+
+		period := PlacesSpecialPeriod{StartDate: placesDate(date, annual)}
+		switch {
+		case s.IsExclude():
+			period.Closed = true
+		case slot.To > EndOfDay:
+			opens, closes, end := placesTime(slot.From), placesTime(slot.To-EndOfDay), placesDate(date.AddDate(0, 0, 1), annual)
+			period.OpenTime, period.CloseTime, period.EndDate = &opens, &closes, &end
+		default:
+			opens, closes := placesTime(slot.From), placesTime(slot.To)
+			period.OpenTime, period.CloseTime = &opens, &closes
+		}
+		special = append(special, period)
+	}
+	return special, nil
+}
+
+// placesDates returns the first and last dates of a date range; annual dates
+// are returned in the leap year 2000
+func placesDates(from, to string) (time.Time, time.Time, error) {
+	if isAnnual(from) != isAnnual(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date range, %v-%v", from, to)
+	}
+	annual := isAnnual(from)
+	if annual {
+		from, to = "2000"+from[1:], "2000"+to[1:]
+	}
+
+	first, err := time.Parse(DateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	last, err := time.Parse(DateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if annual && last.Before(first) {
+		last = last.AddDate(1, 0, 0) // wraps the year boundary
+	}
+	return first, last, nil
+}
+
+func placesTime(t Time) PlacesTime {
+	return PlacesTime{Hours: t.Hour(), Minutes: t.Minute()}
+}
+
+func placesDate(t time.Time, annual bool) PlacesDate {
+	d := PlacesDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}
+	if annual {
+		d.Year = 0
+	}
+	return d
+}
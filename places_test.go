@@ -0,0 +1,168 @@
+package schedule
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestFromPlacesHours(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/places.json")
+	assert.Nil(t, err)
+
+	var h PlacesHours
+	err = json.Unmarshal(data, &h)
+	assert.Nil(t, err)
+
+	ss, err := FromPlacesHours(h)
+	assert.Nil(t, err)
+
+	got := strings.Join(ss.StringSlice(), "\n") + "\n"
+	if *update {
+		err := ioutil.WriteFile("testdata/places.golden", []byte(got), 0644)
+		assert.Nil(t, err)
+	}
+
+	want, err := ioutil.ReadFile("testdata/places.golden")
+	assert.Nil(t, err)
+	assert.Equal(t, string(want), got)
+
+	t.Run("hours", func(t *testing.T) {
+		testCases := map[string]struct {
+			Date time.Time
+			Want []TimeSlot
+		}{
+			"weekday": {
+				Date: time.Date(2020, 12, 22, 0, 0, 0, 0, time.UTC),
+				Want: []TimeSlot{{From: 900, To: 1700}},
+			},
+			"after friday": {
+				Date: time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC),
+				Want: []TimeSlot{{From: 0, To: 200}, {From: 1030, To: 2400}},
+			},
+			"special hours": {
+				Date: time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC),
+				Want: []TimeSlot{{From: 1000, To: 1400}},
+			},
+			"closed": {
+				Date: time.Date(2020, 12, 25, 0, 0, 0, 0, time.UTC),
+				Want: nil,
+			},
+			"annual closure": {
+				Date: time.Date(2021, 7, 4, 0, 0, 0, 0, time.UTC),
+				Want: nil,
+			},
+			"annual closure across the new year": {
+				Date: time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC),
+				Want: nil,
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				got, err := ss.TimeSlots(tc.Date)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Want, got)
+			})
+		}
+	})
+}
+
+func TestSchedules_PlacesHours(t *testing.T) {
+	want, err := ioutil.ReadFile("testdata/places.json")
+	assert.Nil(t, err)
+
+	golden, err := ioutil.ReadFile("testdata/places.golden")
+	assert.Nil(t, err)
+
+	var ss Schedules
+	for _, line := range strings.Split(strings.TrimSpace(string(golden)), "\n") {
+		s, err := Parse(line)
+		assert.Nil(t, err)
+		ss = append(ss, s)
+	}
+
+	h, err := ss.PlacesHours()
+	assert.Nil(t, err)
+
+	got, err := json.MarshalIndent(h, "", "  ")
+	assert.Nil(t, err)
+	assert.Equal(t, string(want), string(got)+"\n")
+
+	t.Run("unsupported", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Want     string
+		}{
+			"recurrence": {
+				Schedule: New(900, 1700).Recur(EveryWeeks(2, "2020-01-04")),
+				Want:     "unable to encode 4:::0900:1700::::every=2w/2020-01-04 as places hours: recurrences are not supported",
+			},
+			"partial exclude": {
+				Schedule: ExcludeTimeRange("2020-01-06", "2020-01-06", 1200, 1300),
+				Want:     "unable to encode 1:2020-01-06:2020-01-06:1200:1300::exclude as places hours: partial day excludes are not supported",
+			},
+			"weekday exclude": {
+				Schedule: ExcludeDateRange("", "", time.Sunday),
+				Want:     "unable to encode 1:::0000:0000:Su:exclude as places hours: excludes require a date range",
+			},
+			"open ended": {
+				Schedule: DateRange("2020-01-06", maxDate, 900, 1700),
+				Want:     "unable to encode 1:2020-01-06:9999-12-31:0900:1700:: as places hours: open ended date ranges are not supported",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				_, err := Schedules{tc.Schedule}.PlacesHours()
+				assert.EqualError(t, err, tc.Want)
+			})
+		}
+	})
+}
+
+func TestFromPlacesHours_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		Input string
+		Want  string
+	}{
+		"day": {
+			Input: `{"regularHours":{"periods":[{"openDay":"DAY_OF_WEEK_UNSPECIFIED","closeDay":"MONDAY","closeTime":{"hours":17}}]}}`,
+			Want:  "invalid regular period 0: invalid openDay, DAY_OF_WEEK_UNSPECIFIED",
+		},
+		"time": {
+			Input: `{"regularHours":{"periods":[{"openDay":"MONDAY","openTime":{"hours":9},"closeDay":"MONDAY","closeTime":{"hours":24,"minutes":30}}]}}`,
+			Want:  "invalid regular period 0: invalid time, 24:30",
+		},
+		"longer than a day": {
+			Input: `{"regularHours":{"periods":[{"openDay":"MONDAY","openTime":{"hours":9},"closeDay":"WEDNESDAY","closeTime":{"hours":17}}]}}`,
+			Want:  "invalid regular period 0: hours must close within a day of opening, 09:00-17:00",
+		},
+		"date": {
+			Input: `{"specialHours":{"specialHourPeriods":[{"startDate":{"year":2021,"month":2,"day":29},"closed":true}]}}`,
+			Want:  "invalid special period 0: invalid date, 2021-02-29",
+		},
+		"end date": {
+			Input: `{"specialHours":{"specialHourPeriods":[{"startDate":{"year":2020,"month":12,"day":24},"openTime":{"hours":9},"endDate":{"month":12,"day":25},"closeTime":{"hours":2}}]}}`,
+			Want:  "invalid special period 0: startDate and endDate must both include a year",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			var h PlacesHours
+			err := json.Unmarshal([]byte(tc.Input), &h)
+			assert.Nil(t, err)
+
+			_, err = FromPlacesHours(h)
+			assert.EqualError(t, err, tc.Want)
+		})
+	}
+}
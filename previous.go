@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// LastOpen returns the most recent interval the Schedules were open at or
+// before t.  See Previous
+func (s Schedules) LastOpen(t time.Time, sans ...TimeSlot) (Interval, error) {
+	return Previous(t, s, sans...)
+}
+
+// Previous returns the most recent interval, within the previous 7 days, the
+// Schedules were open at or before date; Start is when it opened and End is
+// when it closed.  If the Schedules are open at date, End is date.  See
+// PreviousWith for additional options
+func Previous(date time.Time, ss Schedules, sans ...TimeSlot) (Interval, error) {
+	return PreviousWith(date, ss, WithSans(sans...))
+}
+
+// PreviousWith returns the most recent interval the Schedules were open at or
+// before date.  Times are in the location of the Schedules, if any.  Returns
+// an error wrapping ErrNoAvailability if nothing was open within the horizon.
+func PreviousWith(date time.Time, ss Schedules, opts ...Option) (Interval, error) {
+	var (
+		o     = buildOptions(opts...)
+		found *Interval
+	)
+
+	date = localize(date, ss...)
+	r := newResolver(ss)
+	for i := 0; i < o.horizon; i++ {
+		d := alignMidnight(alignMidnight(date).AddDate(0, 0, -i))
+		timeSlots, err := r.timeSlots(d)
+		if err != nil {
+			return Interval{}, err
+		}
+
+		if len(o.sans) > 0 {
+			timeSlots = SubAll(timeSlots, o.sans)
+		}
+
+		for j := len(timeSlots) - 1; j >= 0; j-- {
+			v := Interval{
+				Start: timeSlots[j].From.Align(d),
+				End:   timeSlots[j].To.Align(d),
+			}
+			if !v.Start.Before(date) {
+				continue
+			}
+			if v.End.After(date) {
+				v.End = date
+			}
+
+			switch {
+			case found == nil:
+				found = &v
+			case !v.End.Before(found.Start): // abuts or overlaps
+				if v.Start.Before(found.Start) {
+					found.Start = v.Start
+				}
+			case found.Duration() >= o.duration:
+				return *found, nil
+			default:
+				found = &v
+			}
+		}
+
+		// only overnight hours from the previous date can extend an interval
+		// that opened at midnight
+		if found != nil && found.Start.After(d) && found.Duration() >= o.duration {
+			return *found, nil
+		}
+	}
+
+	if found != nil && found.Duration() >= o.duration {
+		return *found, nil
+	}
+
+	return Interval{}, fmt.Errorf("%w in previous %v days", ErrNoAvailability, o.horizon)
+}
@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestPrevious(t *testing.T) {
+	var (
+		monday   = time.Date(2020, time.July, 27, 0, 0, 0, 0, time.UTC)
+		at       = func(days int, tm Time) time.Time { return tm.Align(monday.AddDate(0, 0, days)) }
+		weekdays = New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	)
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		Date      time.Time
+		Sans      []TimeSlot
+		Want      Interval
+	}{
+		"open now": {
+			Schedules: Schedules{weekdays},
+			Date:      at(0, 1000),
+			Want:      Interval{Start: at(0, 900), End: at(0, 1000)},
+		},
+		"earlier today": {
+			Schedules: Schedules{New(900, 1200), New(1300, 1700)},
+			Date:      at(0, 1230),
+			Want:      Interval{Start: at(0, 900), End: at(0, 1200)},
+		},
+		"before weekend": {
+			Schedules: Schedules{weekdays},
+			Date:      at(0, 800),
+			Want:      Interval{Start: at(-3, 900), End: at(-3, 1700)},
+		},
+		"overnight": {
+			Schedules: Schedules{New(1800, 200, time.Saturday)},
+			Date:      at(-1, 100),
+			Want:      Interval{Start: at(-2, 1800), End: at(-1, 100)},
+		},
+		"overnight closed": {
+			Schedules: Schedules{New(1800, 200, time.Saturday)},
+			Date:      at(0, 1200),
+			Want:      Interval{Start: at(-2, 1800), End: at(-1, 200)},
+		},
+		"sans": {
+			Schedules: Schedules{weekdays},
+			Date:      at(0, 1200),
+			Sans:      []TimeSlot{NewTimeSlot(1000, 1300)},
+			Want:      Interval{Start: at(0, 900), End: at(0, 1000)},
+		},
+		"exclusion": {
+			Schedules: Schedules{weekdays, ExcludeDateRange("2020-07-24", "2020-07-24")},
+			Date:      at(0, 800),
+			Want:      Interval{Start: at(-4, 900), End: at(-4, 1700)},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := tc.Schedules.LastOpen(tc.Date, tc.Sans...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestPreviousWith(t *testing.T) {
+	var (
+		date = time.Date(2020, time.July, 27, 12, 0, 0, 0, time.UTC)
+		ss   = Schedules{New(900, 1700), ExcludeDateRange("2020-06-01", "2020-07-27")}
+	)
+
+	_, err := PreviousWith(date, ss)
+	assert.True(t, errors.Is(err, ErrNoAvailability))
+
+	got, err := PreviousWith(date, ss, WithHorizon(90))
+	assert.Nil(t, err)
+	assert.Equal(t, Interval{
+		Start: time.Date(2020, time.May, 31, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2020, time.May, 31, 17, 0, 0, 0, time.UTC),
+	}, got)
+
+	// Monday morning back to Friday searches 4 days
+	weekdays := Schedules{New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)}
+	_, err = PreviousWith(time.Date(2020, time.July, 27, 8, 0, 0, 0, time.UTC), weekdays, WithHorizon(3))
+	assert.True(t, errors.Is(err, ErrNoAvailability))
+
+	got, err = PreviousWith(time.Date(2020, time.July, 27, 8, 0, 0, 0, time.UTC), weekdays, WithHorizon(4))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.July, 24, 17, 0, 0, 0, time.UTC), got.End)
+
+	got, err = PreviousWith(date, Schedules{New(900, 1000), New(1100, 1700)}, WithDuration(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, Interval{
+		Start: time.Date(2020, time.July, 26, 11, 0, 0, 0, time.UTC),
+		End:   time.Date(2020, time.July, 26, 17, 0, 0, 0, time.UTC),
+	}, got)
+}
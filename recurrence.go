@@ -0,0 +1,475 @@
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	recurNth     = "nth"
+	recurEvery   = "every"
+	recurMonthly = "monthly"
+	recurMonths  = "months"
+)
+
+// Recurrence restricts a Schedule to the dates matching a rule, e.g. the
+// fourth Thursday of November.  A Recurrence is encoded as kind=rule
+//
+//	nth=11/4Th            fourth Thursday of November
+//	nth=11/4Th+1          the day after the fourth Thursday of November
+//	nth=5/-1Mo            last Monday of May
+//	every=3d/2020-01-07   every third day starting 2020-01-07
+//	every=2w/2020-01-04   every other week starting the week of 2020-01-04
+//	monthly=1/15/-1       the 1st, 15th and last day of every month
+//	monthly=-1b           the last business day of every month
+//	months=1/7            every day of January and July
+//
+// Rules other than nth may be limited to months by appending ;months= e.g.
+// monthly=1/15;months=3 for the 1st and 15th of March
+type Recurrence string
+
+// NthWeekday returns a Recurrence matching the nth weekday of month.  A
+// negative n counts back from the end of the month e.g. -1 is the last
+// weekday of the month.  offset shifts the matching date by the number of
+// days provided e.g. 1 for the day after Thanksgiving
+func NthWeekday(month time.Month, n int, weekday time.Weekday, offset int) Recurrence {
+	d, _ := getDayOfTheWeek(weekday)
+
+	buffer := make([]byte, 0, 16)
+	buffer = append(buffer, recurNth...)
+	buffer = append(buffer, '=')
+	buffer = strconv.AppendInt(buffer, int64(month), 10)
+	buffer = append(buffer, '/')
+	buffer = strconv.AppendInt(buffer, int64(n), 10)
+	buffer = append(buffer, d...)
+	if offset > 0 {
+		buffer = append(buffer, '+')
+	}
+	if offset != 0 {
+		buffer = strconv.AppendInt(buffer, int64(offset), 10)
+	}
+	return Recurrence(buffer)
+}
+
+// EveryDays returns a Recurrence matching every n days starting from the
+// anchor date, YYYY-MM-DD
+func EveryDays(n int, anchor string) Recurrence {
+	return every(n, 'd', anchor)
+}
+
+// EveryWeeks returns a Recurrence matching every n weeks starting from the
+// anchor date, YYYY-MM-DD.  Weeks begin on the weekday of the anchor.  A
+// Schedule with an EveryWeeks Recurrence and no weekdays applies to the
+// weekday of the anchor e.g. every other Saturday
+func EveryWeeks(n int, anchor string) Recurrence {
+	return every(n, 'w', anchor)
+}
+
+// DaysOfMonth returns a Recurrence matching the days of every month provided.
+// A negative day counts back from the end of the month e.g. -1 is the last
+// day of the month.  Days beyond the end of a month, e.g. 31 in April, do not
+// match that month
+func DaysOfMonth(days ...int) Recurrence {
+	return monthly(days, false)
+}
+
+// BusinessDaysOfMonth returns a Recurrence matching the nth business day,
+// Monday through Friday, of every month e.g. -1 for the last business day of
+// the month.  Holidays are not taken into account
+func BusinessDaysOfMonth(days ...int) Recurrence {
+	return monthly(days, true)
+}
+
+// Months returns a Recurrence matching every day of the months provided
+func Months(months ...time.Month) Recurrence {
+	buffer := make([]byte, 0, 24)
+	buffer = append(buffer, recurMonths...)
+	buffer = append(buffer, '=')
+	for i, month := range months {
+		if i > 0 {
+			buffer = append(buffer, '/')
+		}
+		buffer = strconv.AppendInt(buffer, int64(month), 10)
+	}
+	return Recurrence(buffer)
+}
+
+// InMonths returns a copy of the Recurrence limited to the months provided
+// e.g. DaysOfMonth(1).InMonths(time.January) for January 1st.  An empty
+// Recurrence becomes Months
+func (r Recurrence) InMonths(months ...time.Month) Recurrence {
+	if r == "" {
+		return Months(months...)
+	}
+	return r + ";" + Months(months...)
+}
+
+func monthly(days []int, business bool) Recurrence {
+	buffer := make([]byte, 0, 24)
+	buffer = append(buffer, recurMonthly...)
+	buffer = append(buffer, '=')
+	for i, day := range days {
+		if i > 0 {
+			buffer = append(buffer, '/')
+		}
+		buffer = strconv.AppendInt(buffer, int64(day), 10)
+		if business {
+			buffer = append(buffer, 'b')
+		}
+	}
+	return Recurrence(buffer)
+}
+
+func every(n int, unit byte, anchor string) Recurrence {
+	buffer := make([]byte, 0, 24)
+	buffer = append(buffer, recurEvery...)
+	buffer = append(buffer, '=')
+	buffer = strconv.AppendInt(buffer, int64(n), 10)
+	buffer = append(buffer, unit, '/')
+	buffer = append(buffer, anchor...)
+	return Recurrence(buffer)
+}
+
+// String implements fmt.Stringer
+func (r Recurrence) String() string {
+	return string(r)
+}
+
+// Contains returns true if the date (but not time) matches the Recurrence
+func (r Recurrence) Contains(date time.Time) bool {
+	rule, err := parseRecurrence(string(r))
+	if err != nil {
+		return false
+	}
+	return rule.contains(date)
+}
+
+// priority returns the Priority of Schedules using this Recurrence
+func (r Recurrence) priority() Priority {
+	if strings.HasPrefix(string(r), recurNth+"=") {
+		return PriorityOverride // holidays override regular hours
+	}
+	return PriorityRegular
+}
+
+// Recur returns a copy of the Schedule restricted to dates matching the
+// Recurrence provided
+func (s Schedule) Recur(r Recurrence) Schedule {
+	fields := bytes.Split(s, []byte(":"))
+	for len(fields) < indexRecurrence {
+		fields = append(fields, nil)
+	}
+	setVersion(fields, 4)
+	fields[indexRecurrence-1] = []byte(r)
+	return bytes.Join(fields, []byte(":"))
+}
+
+// Recurrence returns the Recurrence restricting the Schedule, if any
+func (s Schedule) Recurrence() (Recurrence, bool) {
+	i, j, ok := s.index(indexRecurrence)
+	if !ok {
+		return "", false
+	}
+	return Recurrence(s[i:j]), true
+}
+
+// recurrence returns the parsed Recurrence of the Schedule; nil if the
+// Schedule has none.  An invalid Recurrence matches no dates
+func (s Schedule) recurrence() *recurrence {
+	r, ok := s.Recurrence()
+	if !ok {
+		return nil
+	}
+	rule, err := parseRecurrence(string(r))
+	if err != nil {
+		return &recurrence{}
+	}
+	return &rule
+}
+
+// anchorWeekday returns the weekday implied by a weekly recurrence
+func (r recurrence) anchorWeekday() (time.Weekday, bool) {
+	if r.kind != recurEvery || r.unit != 'w' {
+		return 0, false
+	}
+	return r.anchor.Weekday(), true
+}
+
+// recurrence holds a parsed Recurrence
+type recurrence struct {
+	kind    string
+	month   time.Month
+	n       int
+	weekday time.Weekday
+	offset  int
+	unit    byte      // d or w for every
+	anchor  time.Time // start of every
+	days    []monthDay
+	months  []time.Month // months the recurrence is limited to, if any
+}
+
+// monthDay is a single day of a monthly recurrence
+type monthDay struct {
+	n        int  // 1 based day; negative counts back from the end of the month
+	business bool // n counts business days rather than days
+}
+
+func parseRecurrence(s string) (recurrence, error) {
+	var limit string
+	if i := strings.Index(s, ";"); i >= 0 {
+		s, limit = s[:i], s[i+1:]
+	}
+
+	kind, rule := s, ""
+	if i := strings.Index(s, "="); i >= 0 {
+		kind, rule = s[:i], s[i+1:]
+	}
+
+	var (
+		r   recurrence
+		err error
+	)
+	switch kind {
+	case recurNth:
+		r, err = parseNth(rule)
+	case recurEvery:
+		r, err = parseEvery(rule)
+	case recurMonthly:
+		r, err = parseMonthly(rule)
+	case recurMonths:
+		r, err = parseMonths(rule)
+	default:
+		return recurrence{}, fmt.Errorf("unknown recurrence, %v", s)
+	}
+	if err != nil || limit == "" {
+		return r, err
+	}
+
+	// limited to months e.g. monthly=1;months=3
+	if !strings.HasPrefix(limit, recurMonths+"=") || kind == recurNth || kind == recurMonths {
+		return recurrence{}, fmt.Errorf("invalid recurrence limit, %v", limit)
+	}
+	v, err := parseMonths(strings.TrimPrefix(limit, recurMonths+"="))
+	if err != nil {
+		return recurrence{}, err
+	}
+	r.months = v.months
+	return r, nil
+}
+
+// parseNth parses month/nWeekday[+-offset] e.g. 11/4Th+1
+func parseNth(rule string) (recurrence, error) {
+	invalid := fmt.Errorf("invalid nth weekday recurrence, %v", rule)
+
+	i := strings.Index(rule, "/")
+	if i < 0 {
+		return recurrence{}, invalid
+	}
+
+	month, err := strconv.Atoi(rule[:i])
+	if err != nil || month < 1 || month > 12 {
+		return recurrence{}, invalid
+	}
+
+	rest := rule[i+1:]
+	j := strings.IndexFunc(rest, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+	if j < 0 || j+2 > len(rest) {
+		return recurrence{}, invalid
+	}
+
+	n, err := strconv.Atoi(rest[:j])
+	if err != nil || n == 0 || n < -5 || n > 5 {
+		return recurrence{}, invalid
+	}
+
+	d, ok := getDayOfTheWeekBytes([]byte(rest[j : j+2]))
+	if !ok {
+		return recurrence{}, invalid
+	}
+	weekday, _ := d.Weekday()
+
+	var offset int
+	if v := rest[j+2:]; v != "" {
+		if v[0] != '+' && v[0] != '-' {
+			return recurrence{}, invalid
+		}
+		if offset, err = strconv.Atoi(v); err != nil {
+			return recurrence{}, invalid
+		}
+	}
+
+	return recurrence{
+		kind:    recurNth,
+		month:   time.Month(month),
+		n:       n,
+		weekday: weekday,
+		offset:  offset,
+	}, nil
+}
+
+// parseEvery parses n{d|w}/anchor e.g. 2w/2020-01-04
+func parseEvery(rule string) (recurrence, error) {
+	invalid := fmt.Errorf("invalid every recurrence, %v", rule)
+
+	i := strings.Index(rule, "/")
+	if i < 1 {
+		return recurrence{}, invalid
+	}
+
+	unit := rule[i-1]
+	if unit != 'd' && unit != 'w' {
+		return recurrence{}, invalid
+	}
+
+	n, err := strconv.Atoi(rule[:i-1])
+	if err != nil || n < 1 {
+		return recurrence{}, invalid
+	}
+
+	anchor, err := time.Parse(DateLayout, rule[i+1:])
+	if err != nil {
+		return recurrence{}, invalid
+	}
+
+	return recurrence{
+		kind:   recurEvery,
+		n:      n,
+		unit:   unit,
+		anchor: anchor,
+	}, nil
+}
+
+// parseMonthly parses day[b]/day[b]/... e.g. 1/15/-1b
+func parseMonthly(rule string) (recurrence, error) {
+	invalid := fmt.Errorf("invalid monthly recurrence, %v", rule)
+
+	var days []monthDay
+	for _, v := range strings.Split(rule, "/") {
+		business := strings.HasSuffix(v, "b")
+		n, err := strconv.Atoi(strings.TrimSuffix(v, "b"))
+		switch {
+		case err != nil, n == 0, n < -31, n > 31, business && (n < -23 || n > 23):
+			return recurrence{}, invalid
+		}
+		days = append(days, monthDay{n: n, business: business})
+	}
+
+	return recurrence{
+		kind: recurMonthly,
+		days: days,
+	}, nil
+}
+
+// parseMonths parses month/month/... e.g. 1/7
+func parseMonths(rule string) (recurrence, error) {
+	var months []time.Month
+	for _, v := range strings.Split(rule, "/") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 12 {
+			return recurrence{}, fmt.Errorf("invalid months recurrence, %v", rule)
+		}
+		months = append(months, time.Month(n))
+	}
+
+	return recurrence{
+		kind:   recurMonths,
+		months: months,
+	}, nil
+}
+
+func (r recurrence) contains(date time.Time) bool {
+	if len(r.months) > 0 && !r.inMonths(date.Month()) {
+		return false
+	}
+
+	switch r.kind {
+	case recurNth:
+		// shift back to the nth weekday itself
+		base := time.Date(date.Year(), date.Month(), date.Day()-r.offset, 0, 0, 0, 0, time.UTC)
+		if base.Month() != r.month || base.Weekday() != r.weekday {
+			return false
+		}
+		if r.n > 0 {
+			return (base.Day()-1)/7+1 == r.n
+		}
+		last := time.Date(base.Year(), base.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return (last-base.Day())/7+1 == -r.n
+
+	case recurEvery:
+		days := int(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Sub(r.anchor) / (24 * time.Hour))
+		if days < 0 {
+			return false
+		}
+		if r.unit == 'w' {
+			return (days/7)%r.n == 0
+		}
+		return days%r.n == 0
+
+	case recurMonthly:
+		for _, day := range r.days {
+			if day.matches(date) {
+				return true
+			}
+		}
+		return false
+
+	case recurMonths:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// inMonths returns true if month is one of the months the recurrence is
+// limited to
+func (r recurrence) inMonths(month time.Month) bool {
+	for _, m := range r.months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if date is the day of its month described by d
+func (d monthDay) matches(date time.Time) bool {
+	var (
+		year, month, day = date.Date()
+		last             = time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	)
+
+	if !d.business {
+		if d.n > 0 {
+			return day == d.n
+		}
+		return day == last+d.n+1
+	}
+
+	if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+
+	// count the business days from the start or end of the month through date
+	var (
+		n          int
+		start, end = 1, day
+	)
+	if d.n < 0 {
+		start, end = day, last
+	}
+	for i := start; i <= end; i++ {
+		switch time.Date(year, month, i, 0, 0, 0, 0, time.UTC).Weekday() {
+		case time.Saturday, time.Sunday:
+		default:
+			n++
+		}
+	}
+	if d.n < 0 {
+		return n == -d.n
+	}
+	return n == d.n
+}
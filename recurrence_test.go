@@ -0,0 +1,346 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestNthWeekday(t *testing.T) {
+	var (
+		thanksgiving = NthWeekday(time.November, 4, time.Thursday, 0)
+		dayAfter     = NthWeekday(time.November, 4, time.Thursday, 1)
+		memorialDay  = NthWeekday(time.May, -1, time.Monday, 0)
+		dayBefore    = NthWeekday(time.September, 1, time.Monday, -3)
+	)
+
+	assert.Equal(t, Recurrence("nth=11/4Th"), thanksgiving)
+	assert.Equal(t, Recurrence("nth=11/4Th+1"), dayAfter)
+	assert.Equal(t, Recurrence("nth=5/-1Mo"), memorialDay)
+	assert.Equal(t, Recurrence("nth=9/1Mo-3"), dayBefore)
+
+	testCases := map[string]struct {
+		Recurrence Recurrence
+		Date       string
+		Want       bool
+	}{
+		"thanksgiving 2020":          {Recurrence: thanksgiving, Date: "2020-11-26", Want: true},
+		"thanksgiving 2021":          {Recurrence: thanksgiving, Date: "2021-11-25", Want: true},
+		"third thursday":             {Recurrence: thanksgiving, Date: "2020-11-19", Want: false},
+		"wrong month":                {Recurrence: thanksgiving, Date: "2020-10-22", Want: false},
+		"day after thanksgiving":     {Recurrence: dayAfter, Date: "2020-11-27", Want: true},
+		"not day after thanksgiving": {Recurrence: dayAfter, Date: "2020-11-26", Want: false},
+		"memorial day 2020":          {Recurrence: memorialDay, Date: "2020-05-25", Want: true},
+		"memorial day 2021":          {Recurrence: memorialDay, Date: "2021-05-31", Want: true},
+		"not last monday":            {Recurrence: memorialDay, Date: "2021-05-24", Want: false},
+		"offset across month":        {Recurrence: dayBefore, Date: "2020-09-04", Want: true},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			date, err := time.Parse(DateLayout, tc.Date)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, tc.Recurrence.Contains(date))
+		})
+	}
+}
+
+func TestSchedule_Recur(t *testing.T) {
+	var (
+		thanksgiving = NthWeekday(time.November, 4, time.Thursday, 0)
+		closed       = ExcludeDateRange("", "").Recur(thanksgiving)
+		dayAfter     = New(1000, 1400).Recur(NthWeekday(time.November, 4, time.Thursday, 1))
+		ss           = Schedules{New(900, 1700), closed, dayAfter}
+		date         = func(s string) time.Time {
+			v, err := time.Parse(DateLayout, s)
+			assert.Nil(t, err)
+			return v
+		}
+	)
+
+	assert.Equal(t, "4:::0000:0000::exclude::nth=11/4Th", closed.String())
+	assert.Equal(t, PriorityExclude, closed.Priority())
+	assert.Equal(t, PriorityOverride, dayAfter.Priority())
+
+	r, ok := closed.Recurrence()
+	assert.True(t, ok)
+	assert.Equal(t, thanksgiving, r)
+
+	t.Run("contains", func(t *testing.T) {
+		assert.True(t, closed.Contains(date("2020-11-26")))
+		assert.False(t, closed.Contains(date("2020-11-25")))
+	})
+
+	t.Run("time slots", func(t *testing.T) {
+		got, err := ss.TimeSlots(date("2020-11-26"))
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+
+		got, err = ss.TimeSlots(date("2020-11-27"))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(1000, 1400)}, got)
+
+		got, err = ss.TimeSlots(date("2020-11-25"))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(900, 1700)}, got)
+	})
+
+	t.Run("hours", func(t *testing.T) {
+		_, ok := Hours(date("2020-11-26"), ss...)
+		assert.False(t, ok)
+	})
+
+	t.Run("add date", func(t *testing.T) {
+		got := AddDate(date("2020-11-25"), 1, ss...)
+		assert.Equal(t, "2020-11-27", got.Format(DateLayout))
+	})
+
+	t.Run("in", func(t *testing.T) {
+		got := closed.In(time.UTC)
+		assert.Equal(t, "4:::0000:0000::exclude:UTC:nth=11/4Th", got.String())
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		_, err := Parse(closed.String())
+		assert.Nil(t, err)
+
+		_, err = Parse("4:::0000:0000::exclude::nth=13/4Th")
+		assert.EqualError(t, err, `invalid Schedule, "4:::0000:0000::exclude::nth=13/4Th": recurrence: invalid nth weekday recurrence, 13/4Th`)
+
+		_, err = Parse("4:::0000:0000::exclude::yearly=1")
+		assert.EqualError(t, err, `invalid Schedule, "4:::0000:0000::exclude::yearly=1": recurrence: unknown recurrence, yearly=1`)
+	})
+}
+
+func TestEvery(t *testing.T) {
+	var (
+		everyOtherSaturday = New(900, 1300).Recur(EveryWeeks(2, "2020-01-04"))
+		everyThirdTuesday  = New(1300, 1700, time.Tuesday).Recur(EveryWeeks(3, "2020-01-07"))
+		everyThirdDay      = New(800, 1200).Recur(EveryDays(3, "2020-01-07"))
+		date               = func(s string) time.Time {
+			v, err := time.Parse(DateLayout, s)
+			assert.Nil(t, err)
+			return v
+		}
+	)
+
+	assert.Equal(t, "4:::0900:1300::::every=2w/2020-01-04", everyOtherSaturday.String())
+	assert.Equal(t, PriorityRegular, everyOtherSaturday.Priority())
+
+	t.Run("contains", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Date     string
+			Want     bool
+		}{
+			"every other saturday - anchor":     {Schedule: everyOtherSaturday, Date: "2020-01-04", Want: true},
+			"every other saturday - off week":   {Schedule: everyOtherSaturday, Date: "2020-01-11", Want: false},
+			"every other saturday - on week":    {Schedule: everyOtherSaturday, Date: "2020-01-18", Want: true},
+			"every other saturday - sunday":     {Schedule: everyOtherSaturday, Date: "2020-01-19", Want: false},
+			"every other saturday - before":     {Schedule: everyOtherSaturday, Date: "2019-12-21", Want: false},
+			"every third tuesday - anchor":      {Schedule: everyThirdTuesday, Date: "2020-01-07", Want: true},
+			"every third tuesday - next week":   {Schedule: everyThirdTuesday, Date: "2020-01-14", Want: false},
+			"every third tuesday - third week":  {Schedule: everyThirdTuesday, Date: "2020-01-28", Want: true},
+			"every third tuesday - wednesday":   {Schedule: everyThirdTuesday, Date: "2020-01-29", Want: false},
+			"every third day - anchor":          {Schedule: everyThirdDay, Date: "2020-01-07", Want: true},
+			"every third day - next day":        {Schedule: everyThirdDay, Date: "2020-01-08", Want: false},
+			"every third day - third day":       {Schedule: everyThirdDay, Date: "2020-01-10", Want: true},
+			"every third day - across dst":      {Schedule: everyThirdDay, Date: "2020-03-10", Want: true},
+			"every third day - across new year": {Schedule: everyThirdDay, Date: "2021-01-01", Want: true},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				assert.Equal(t, tc.Want, tc.Schedule.Contains(date(tc.Date)))
+			})
+		}
+	})
+
+	t.Run("contains weekday", func(t *testing.T) {
+		assert.True(t, everyOtherSaturday.ContainsWeekday(time.Saturday))
+		assert.False(t, everyOtherSaturday.ContainsWeekday(time.Sunday))
+		assert.True(t, everyThirdTuesday.ContainsWeekday(time.Tuesday))
+		assert.True(t, everyThirdDay.ContainsWeekday(time.Sunday))
+	})
+
+	t.Run("time slots", func(t *testing.T) {
+		ss := Schedules{New(900, 1700, time.Monday), everyOtherSaturday}
+
+		got, err := ss.TimeSlots(date("2020-01-18"))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(900, 1300)}, got)
+
+		got, err = ss.TimeSlots(date("2020-01-11"))
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("next", func(t *testing.T) {
+		got, err := NextWith(date("2020-01-05"), Schedules{everyOtherSaturday}, WithHorizon(14))
+		assert.Nil(t, err)
+		assert.Equal(t, NewTime(9, 0).Align(date("2020-01-18")), got)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		_, err := Parse(everyThirdDay.String())
+		assert.Nil(t, err)
+
+		_, err = Parse("4:::0900:1300::::every=0w/2020-01-04")
+		assert.EqualError(t, err, `invalid Schedule, "4:::0900:1300::::every=0w/2020-01-04": recurrence: invalid every recurrence, 0w/2020-01-04`)
+
+		_, err = Parse("4:::0900:1300::::every=2m/2020-01-04")
+		assert.EqualError(t, err, `invalid Schedule, "4:::0900:1300::::every=2m/2020-01-04": recurrence: invalid every recurrence, 2m/2020-01-04`)
+	})
+}
+
+func TestDaysOfMonth(t *testing.T) {
+	var (
+		billing         = New(900, 1200).Recur(DaysOfMonth(1, 15, -1))
+		lastBusinessDay = New(1300, 1700).Recur(BusinessDaysOfMonth(-1))
+		date            = func(s string) time.Time {
+			v, err := time.Parse(DateLayout, s)
+			assert.Nil(t, err)
+			return v
+		}
+	)
+
+	assert.Equal(t, "4:::0900:1200::::monthly=1/15/-1", billing.String())
+	assert.Equal(t, "4:::1300:1700::::monthly=-1b", lastBusinessDay.String())
+	assert.Equal(t, PriorityRegular, billing.Priority())
+
+	t.Run("contains", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Date     string
+			Want     bool
+		}{
+			"first":                          {Schedule: billing, Date: "2020-02-01", Want: true},
+			"fifteenth":                      {Schedule: billing, Date: "2020-02-15", Want: true},
+			"sixteenth":                      {Schedule: billing, Date: "2020-02-16", Want: false},
+			"last day - leap year":           {Schedule: billing, Date: "2020-02-29", Want: true},
+			"last day - not leap year":       {Schedule: billing, Date: "2021-02-28", Want: true},
+			"second to last day":             {Schedule: New(900, 1200).Recur(DaysOfMonth(-2)), Date: "2020-04-29", Want: true},
+			"31st - short month":             {Schedule: New(900, 1200).Recur(DaysOfMonth(31)), Date: "2020-04-30", Want: false},
+			"last business day - weekday":    {Schedule: lastBusinessDay, Date: "2020-01-31", Want: true},
+			"last business day - weekend":    {Schedule: lastBusinessDay, Date: "2020-05-29", Want: true},
+			"last business day - saturday":   {Schedule: lastBusinessDay, Date: "2020-05-30", Want: false},
+			"last business day - day before": {Schedule: lastBusinessDay, Date: "2020-01-30", Want: false},
+			"first business day":             {Schedule: New(900, 1200).Recur(BusinessDaysOfMonth(1)), Date: "2020-02-03", Want: true},
+			"first business day - saturday":  {Schedule: New(900, 1200).Recur(BusinessDaysOfMonth(1)), Date: "2020-02-01", Want: false},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				assert.Equal(t, tc.Want, tc.Schedule.Contains(date(tc.Date)))
+			})
+		}
+	})
+
+	t.Run("time slots", func(t *testing.T) {
+		ss := Schedules{billing, lastBusinessDay}
+
+		got, err := ss.TimeSlots(date("2020-01-31"))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(900, 1200), NewTimeSlot(1300, 1700)}, got)
+
+		got, err = ss.TimeSlots(date("2020-01-15"))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(900, 1200)}, got)
+
+		got, err = ss.TimeSlots(date("2020-01-16"))
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		_, err := Parse(billing.String())
+		assert.Nil(t, err)
+
+		for _, rule := range []string{"monthly=", "monthly=0", "monthly=32", "monthly=1//15", "monthly=1x", "monthly=24b"} {
+			_, err := Parse("2:::0900:1200::::" + rule)
+			assert.NotNil(t, err, rule)
+		}
+	})
+}
+
+func TestMonths(t *testing.T) {
+	var (
+		summer  = New(900, 1700).Recur(Months(time.June, time.July, time.August))
+		billing = New(900, 1200).Recur(DaysOfMonth(1, 15).InMonths(time.March))
+		date    = func(s string) time.Time {
+			v, err := time.Parse(DateLayout, s)
+			assert.Nil(t, err)
+			return v
+		}
+	)
+
+	assert.Equal(t, "4:::0900:1700::::months=6/7/8", summer.String())
+	assert.Equal(t, "4:::0900:1200::::monthly=1/15;months=3", billing.String())
+	assert.Equal(t, Recurrence("months=1"), Recurrence("").InMonths(time.January))
+	assert.Equal(t, PriorityRegular, summer.Priority())
+	assert.Equal(t, PriorityRegular, billing.Priority())
+
+	t.Run("contains", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Date     string
+			Want     bool
+		}{
+			"month":                   {Schedule: summer, Date: "2020-07-04", Want: true},
+			"other month":             {Schedule: summer, Date: "2020-09-01", Want: false},
+			"day of month":            {Schedule: billing, Date: "2020-03-15", Want: true},
+			"day of other month":      {Schedule: billing, Date: "2020-04-15", Want: false},
+			"other day of month":      {Schedule: billing, Date: "2020-03-16", Want: false},
+			"every other week in may": {Schedule: New(900, 1200).Recur(EveryWeeks(2, "2020-01-04").InMonths(time.May)), Date: "2020-05-09", Want: true},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				assert.Equal(t, tc.Want, tc.Schedule.Contains(date(tc.Date)))
+			})
+		}
+	})
+
+	t.Run("regular hours", func(t *testing.T) {
+		ss := Schedules{New(1300, 1700, time.Sunday), New(900, 1200, time.Sunday).Recur(Months(time.March))}
+
+		got, err := ss.TimeSlots(date("2020-03-01"))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(900, 1200), NewTimeSlot(1300, 1700)}, got)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		_, err := Parse(billing.String())
+		assert.Nil(t, err)
+
+		for _, rule := range []string{"months=", "months=0", "months=13", "monthly=1;months=", "monthly=1;every=2d/2020-01-01", "months=1;months=2", "nth=11/4Th;months=11"} {
+			_, err := Parse("4:::0900:1200::::" + rule)
+			assert.NotNil(t, err, rule)
+		}
+	})
+}
+
+func BenchmarkTimeSlots_Recurrence(b *testing.B) {
+	var (
+		date = time.Date(2020, time.November, 2, 0, 0, 0, 0, time.UTC)
+		ss   = Schedules{
+			New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday),
+			New(1000, 1400).Recur(EveryWeeks(2, "2020-01-04")),
+			ExcludeDateRange("", "").Recur(NthWeekday(time.November, 4, time.Thursday, 0)),
+		}
+		got int
+	)
+
+	for i := 0; i < b.N; i++ {
+		it := ss.Iter(date)
+		for j := 0; j < 30; j++ {
+			if _, ok := it.Next(); ok {
+				got++
+			}
+		}
+	}
+	if got == 0 {
+		b.Fatalf("got %v; want > 0", got)
+	}
+}
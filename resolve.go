@@ -0,0 +1,142 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Priority determines which Schedules apply when more than one Schedule
+// matches a date.  Higher priorities take precedence over lower priorities.
+type Priority int
+
+const (
+	// PriorityRegular applies to recurring Schedules e.g. New
+	PriorityRegular Priority = iota
+	// PriorityOverride applies to Schedules scoped to dates e.g. DateRange or
+	// NthWeekday holidays
+	PriorityOverride
+	// PriorityExclude applies to exclusions e.g. ExcludeDateRange
+	PriorityExclude
+)
+
+// Priority returns the precedence of the Schedule when resolving hours
+func (s Schedule) Priority() Priority {
+	switch {
+	case s.IsExclude():
+		return PriorityExclude
+	case s.HasDateRange():
+		return PriorityOverride
+	}
+	if r, ok := s.Recurrence(); ok {
+		return r.priority()
+	}
+	return PriorityRegular
+}
+
+// TimeSlots returns the timeslots for the date.  TimeSlots is the resolution
+// engine behind every query in this package; schedules matching the date are
+// resolved as follows:
+//
+//  1. an exclude that covers the whole day closes the date
+//  2. the include schedules with the highest Priority are unioned; a date
+//     range override replaces the regular hours rather than adding to them
+//  3. windows from partial day excludes are removed
+//
+// Overnight TimeSlots that start on the date run past EndOfDay while the
+// after midnight portion of overnight TimeSlots from the previous date are
+// included from Midnight
+func TimeSlots(date time.Time, ss ...Schedule) ([]TimeSlot, error) {
+	return newResolver(ss).timeSlots(date)
+}
+
+// resolver resolves the hours of Schedules; each Recurrence is parsed once
+// rather than for every date evaluated
+type resolver struct {
+	ss    []Schedule
+	rules []*recurrence // parsed Recurrence of each Schedule, if any
+}
+
+func newResolver(ss []Schedule) resolver {
+	rules := make([]*recurrence, len(ss))
+	for i, s := range ss {
+		rules[i] = s.recurrence()
+	}
+	return resolver{ss: ss, rules: rules}
+}
+
+// timeSlots implements TimeSlots
+func (r resolver) timeSlots(date time.Time) ([]TimeSlot, error) {
+	date = localize(date, r.ss...)
+
+	slots, windows, err := r.scheduled(date)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, excluded, err := r.scheduled(date.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range excluded {
+		if tail, ok := window.Tail(); ok {
+			windows = append(windows, tail)
+		}
+	}
+	slots = SubAll(slots, windows)
+
+	for _, slot := range SubAll(previous, excluded) {
+		if tail, ok := slot.Tail(); ok {
+			slots = append(slots, tail)
+		}
+	}
+
+	slots = Union(slots...)
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].From < slots[j].From
+	})
+
+	return slots, nil
+}
+
+// scheduled returns the time slots scheduled for date along with the windows
+// excluded from them
+func (r resolver) scheduled(date time.Time) ([]TimeSlot, []TimeSlot, error) {
+	var (
+		priority Priority
+		slots    []TimeSlot
+		windows  []TimeSlot
+	)
+
+	for i, s := range r.ss {
+		if zone, ok := s.Zone(); ok {
+			if _, ok := loadLocation(zone); !ok {
+				return nil, nil, fmt.Errorf("unknown zone, %v", zone)
+			}
+		}
+		if !s.contains(date, r.rules[i]) {
+			continue
+		}
+		if s.ExcludesAllDay() {
+			return nil, nil, nil // excluded date
+		}
+
+		timeSlot, err := s.TimeSlot()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch p := s.Priority(); {
+		case p == PriorityExclude:
+			windows = append(windows, timeSlot)
+		case len(slots) == 0 || p > priority:
+			priority, slots = p, []TimeSlot{timeSlot}
+		case p == priority:
+			slots = append(slots, timeSlot)
+		}
+	}
+
+	return slots, windows, nil
+}
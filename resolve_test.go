@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestSchedule_Priority(t *testing.T) {
+	assert.Equal(t, PriorityRegular, New(800, 1800).Priority())
+	assert.Equal(t, PriorityOverride, DateRange("2020-07-20", "2020-07-20", 800, 1200).Priority())
+	assert.Equal(t, PriorityExclude, ExcludeDateRange("2020-07-20", "2020-07-20").Priority())
+	assert.Equal(t, PriorityExclude, ExcludeTimeRange("2020-07-20", "2020-07-20", 1200, 1300).Priority())
+}
+
+// TestConformance verifies every public entry point resolves schedules the
+// same way
+func TestConformance(t *testing.T) {
+	var (
+		date     = time.Date(2020, time.July, 20, 0, 0, 0, 0, time.UTC) // Monday
+		today    = date.Format(DateLayout)
+		tomorrow = date.AddDate(0, 0, 1).Format(DateLayout)
+		regular  = New(800, 1800)
+		weekdays = New(900, 1700, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	)
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		Want      []TimeSlot
+	}{
+		"regular": {
+			Schedules: Schedules{regular},
+			Want:      []TimeSlot{NewTimeSlot(800, 1800)},
+		},
+		"regular unioned": {
+			Schedules: Schedules{New(1700, 2000), regular, New(600, 700)},
+			Want:      []TimeSlot{NewTimeSlot(600, 700), NewTimeSlot(800, 2000)},
+		},
+		"weekday mismatch": {
+			Schedules: Schedules{New(800, 1800, time.Sunday)},
+			Want:      nil,
+		},
+		"override replaces regular": {
+			Schedules: Schedules{regular, weekdays, DateRange(today, today, 1000, 1400)},
+			Want:      []TimeSlot{NewTimeSlot(1000, 1400)},
+		},
+		"override order independent": {
+			Schedules: Schedules{DateRange(today, today, 1000, 1400), weekdays, regular},
+			Want:      []TimeSlot{NewTimeSlot(1000, 1400)},
+		},
+		"overrides unioned": {
+			Schedules: Schedules{regular, DateRange(today, today, 1000, 1200), DateRange(today, tomorrow, 1500, 1600)},
+			Want:      []TimeSlot{NewTimeSlot(1000, 1200), NewTimeSlot(1500, 1600)},
+		},
+		"other override ignored": {
+			Schedules: Schedules{regular, DateRange(tomorrow, tomorrow, 1000, 1200)},
+			Want:      []TimeSlot{NewTimeSlot(800, 1800)},
+		},
+		"exclude beats override": {
+			Schedules: Schedules{regular, DateRange(today, today, 1000, 1400), ExcludeDateRange(today, today)},
+			Want:      nil,
+		},
+		"exclude weekday": {
+			Schedules: Schedules{regular, ExcludeDateRange("", "", time.Monday)},
+			Want:      nil,
+		},
+		"partial exclude trims override": {
+			Schedules: Schedules{regular, DateRange(today, today, 1000, 1400), ExcludeTimeRange(today, today, 1200, 1300)},
+			Want:      []TimeSlot{NewTimeSlot(1000, 1200), NewTimeSlot(1300, 1400)},
+		},
+		"overnight tail": {
+			Schedules: Schedules{New(1800, 200, time.Sunday), weekdays},
+			Want:      []TimeSlot{NewTimeSlot(Midnight, 200), NewTimeSlot(900, 1700)},
+		},
+		"overnight start": {
+			Schedules: Schedules{New(2000, 300, time.Monday)},
+			Want:      []TimeSlot{NewTimeSlot(2000, 300)},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			ss := append(Schedules(nil), tc.Schedules...)
+
+			got, err := TimeSlots(date, ss...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got, "TimeSlots")
+
+			hours, ok := Hours(date, ss...)
+			assert.Equal(t, len(tc.Want) > 0, ok, "Hours")
+			assert.Equal(t, tc.Want, hours, "Hours")
+
+			available := Availability(date, ss, nil)
+			assert.Equal(t, tc.Want, available, "Availability")
+
+			after, err := After(date, ss...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, after, "After")
+
+			next, err := Next(date, ss)
+			if len(tc.Want) > 0 {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Want[0].From.Align(date), next, "Next")
+			} else if err == nil {
+				assert.NotEqual(t, date.Format(DateLayout), next.Format(DateLayout), "Next")
+			}
+
+			assert.Equal(t, tc.Schedules, ss, "schedules must not be modified")
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,13 +16,15 @@ import (
 const exclude = "exclude"
 
 const (
-	indexVersion  = 1
-	indexDateFrom = 2
-	indexDateTo   = 3
-	indexFrom     = 4
-	indexTo       = 5
-	indexWeekdays = 6
-	indexExclude  = 7
+	indexVersion    = 1
+	indexDateFrom   = 2
+	indexDateTo     = 3
+	indexFrom       = 4
+	indexTo         = 5
+	indexWeekdays   = 6
+	indexExclude    = 7
+	indexZone       = 8
+	indexRecurrence = 9
 )
 
 type DayOfTheWeek string
@@ -117,7 +118,19 @@ var (
 
 // Schedule
 // SuMoTuWeThFrSa
-// version:date-from:date-to:weekdays:from-time:to-time:exclude|include
+// version:date-from:date-to:from-time:to-time:weekdays:exclude|include
+//
+// Version 2 adds the IANA zone the schedule is evaluated in
+// version:date-from:date-to:from-time:to-time:weekdays:exclude|include:zone
+//
+// Version 3 adds annual dates, --MM-DD, that recur every year, with the same
+// fields as version 2
+//
+// Version 4 adds an optional Recurrence rule
+// version:date-from:date-to:from-time:to-time:weekdays:exclude|include:zone:recurrence
+//
+// Each version only appends fields to the previous version so every version
+// can be read by Parse.  Trailing empty fields may be omitted.
 type Schedule []byte
 
 func New(from, to Time, weekdays ...time.Weekday) Schedule {
@@ -152,13 +165,8 @@ func (s Schedule) index(n int) (int, int, bool) {
 }
 
 func (s Schedule) validate() error {
-	if _, _, ok := s.index(indexVersion); !ok {
-		return fmt.Errorf("invalid Schedule: missing version")
-	}
-	if _, _, ok := s.index(indexTo); !ok {
-		return fmt.Errorf("invalid Schedule: missing to")
-	}
-	return nil
+	_, err := Parse(string(s))
+	return err
 }
 
 // MarshalDynamoDBAttributeValue marshals Schedule for dynamodb
@@ -174,6 +182,12 @@ func (s Schedule) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.String())
 }
 
+// MarshalText implements encoding.TextMarshaler so text based encoders such as
+// YAML and TOML write the Schedule as a string; an unset Schedule is empty
+func (s Schedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 // UnmarshalDynamoDBAttributeValue unmarshals Schedule for dynamodb
 func (s *Schedule) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
 	if item == nil || item.S == nil {
@@ -189,8 +203,18 @@ func (s *Schedule) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue
 	return nil
 }
 
-// MarshalJSON implements json.Unmarshaler
+// UnmarshalJSON implements json.Unmarshaler.  Both the compact string and the
+// structured form written by Structured are accepted
 func (s *Schedule) UnmarshalJSON(data []byte) error {
+	if isJSONObject(data) {
+		v, err := unmarshalFields(data)
+		if err != nil {
+			return err
+		}
+		*s = v
+		return nil
+	}
+
 	var str string
 	if err := json.Unmarshal(data, &str); err != nil {
 		return fmt.Errorf("unable to unmarshal Schedule: %w", err)
@@ -201,7 +225,25 @@ func (s *Schedule) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// DateFrom extracts the from date from the schedule
+// UnmarshalText implements encoding.TextUnmarshaler; the Schedule is validated.
+// Empty text, as written by MarshalText for an unset Schedule, is a nil Schedule
+func (s *Schedule) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = nil
+		return nil
+	}
+
+	v := Schedule(string(text))
+	if err := v.validate(); err != nil {
+		return err
+	}
+
+	*s = v
+	return nil
+}
+
+// DateFrom extracts the from date from the schedule.  Annual dates are
+// returned as --MM-DD
 func (s Schedule) DateFrom() (string, bool) {
 	i, j, ok := s.index(indexDateFrom)
 	if !ok {
@@ -211,7 +253,8 @@ func (s Schedule) DateFrom() (string, bool) {
 	return string(s[i:j]), true
 }
 
-// DateTo extracts the from date from the schedule
+// DateTo extracts the to date from the schedule.  Annual dates are returned
+// as --MM-DD
 func (s Schedule) DateTo() (string, bool) {
 	i, j, ok := s.index(indexDateTo)
 	if !ok {
@@ -221,9 +264,24 @@ func (s Schedule) DateTo() (string, bool) {
 	return string(s[i:j]), true
 }
 
-// Contains matches the provided date (but not time)
+// Contains matches the provided date (but not time).  If the Schedule has a
+// location, date is first converted to that location.  A Schedule with an
+// unknown location matches no dates, as TimeSlots returns an error for it
 func (s Schedule) Contains(date time.Time) bool {
-	if !s.ContainsWeekday(date.Weekday()) {
+	return s.contains(date, s.recurrence())
+}
+
+// contains implements Contains with the parsed Recurrence of the Schedule
+func (s Schedule) contains(date time.Time, rule *recurrence) bool {
+	if zone, ok := s.Zone(); ok {
+		loc, ok := loadLocation(zone)
+		if !ok {
+			return false
+		}
+		date = date.In(loc)
+	}
+
+	if !s.containsWeekday(date.Weekday(), rule) {
 		return false
 	}
 
@@ -234,7 +292,21 @@ func (s Schedule) Contains(date time.Time) bool {
 		str         = date.AppendFormat(buf[:0], DateLayout)
 	)
 
+	if rule != nil && !rule.contains(date) {
+		return false
+	}
+
 	switch {
+	case fok && tok && bytes.HasPrefix(s[fi:fj], []byte("--")): // annual
+		// compare -MM-DD
+		md := str[len("2006"):]
+		from := bytes.Compare(s[fi+1:fj], md)
+		to := bytes.Compare(s[ti+1:tj], md)
+		if bytes.Compare(s[fi:fj], s[ti:tj]) > 0 { // wraps the year boundary
+			return from <= 0 || to >= 0
+		}
+		return from <= 0 && to >= 0
+
 	case fok && tok:
 		from := bytes.Compare(s[fi:fj], str)
 		to := bytes.Compare(s[ti:tj], str)
@@ -245,12 +317,24 @@ func (s Schedule) Contains(date time.Time) bool {
 	}
 }
 
-// ContainsWeekday matches the weekday only
+// ContainsWeekday matches the weekday only.  Schedules recurring weekly with no
+// weekdays match the weekday of the Recurrence anchor
 func (s Schedule) ContainsWeekday(weekday time.Weekday) bool {
+	return s.containsWeekday(weekday, s.recurrence())
+}
+
+// containsWeekday implements ContainsWeekday with the parsed Recurrence of the
+// Schedule
+func (s Schedule) containsWeekday(weekday time.Weekday, rule *recurrence) bool {
 	if i, j, ok := s.index(indexWeekdays); ok {
 		d, _ := getDayOfTheWeek(weekday)
 		return strings.Contains(string(s[i:j]), d.String())
 	}
+	if rule != nil {
+		if w, ok := rule.anchorWeekday(); ok {
+			return w == weekday
+		}
+	}
 	return true
 }
 
@@ -279,6 +363,23 @@ func (s Schedule) IsExclude() bool {
 	return ok
 }
 
+// ExcludesAllDay returns true if the Schedule excludes the entire day.  An
+// exclude Schedule with distinct from and to times excludes only that window.
+func (s Schedule) ExcludesAllDay() bool {
+	if !s.IsExclude() {
+		return false
+	}
+	from, err := s.From()
+	if err != nil {
+		return true
+	}
+	to, err := s.To()
+	if err != nil {
+		return true
+	}
+	return from == to
+}
+
 func (s Schedule) String() string {
 	return string(s)
 }
@@ -293,10 +394,7 @@ func (s Schedule) TimeSlot() (TimeSlot, error) {
 		return TimeSlot{}, err
 	}
 
-	return TimeSlot{
-		From: from,
-		To:   to,
-	}, nil
+	return NewTimeSlot(from, to), nil
 }
 
 func (s Schedule) To() (Time, error) {
@@ -351,24 +449,15 @@ func (s Schedules) Contains(want Schedule) bool {
 	return false
 }
 
-// ContainsTime returns true if any of the schedules contains the requested time
+// ContainsTime returns true if the schedules are open at the requested time.
+// Equivalent to IsOpen
 func (s Schedules) ContainsTime(t time.Time) bool {
-	// check excludes first; any excludes will return false
-	for _, v := range s {
-		if v.IsExclude() && v.Contains(t) {
-			return false
-		}
-	}
-
-	// then check included times
-	for _, v := range s {
-		if !v.IsExclude() && v.Contains(t) {
-			return true
-		}
-	}
+	return IsOpen(t, s...)
+}
 
-	// otherwise, default to not included
-	return false
+// IsOpen returns true if the schedules are open at the requested time
+func (s Schedules) IsOpen(t time.Time) bool {
+	return IsOpen(t, s...)
 }
 
 func (s Schedules) MarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
@@ -382,6 +471,18 @@ func (s Schedules) MarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue)
 	return nil
 }
 
+// MarshalJSON implements json.Marshaler; Schedules are written as an array
+// of strings rather than the comma separated text of MarshalText
+func (s Schedules) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Schedule(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.  Schedules are written
+// separated by commas e.g. 1:::0800:1200:MoTu:,1:::1300:1700:MoTu:
+func (s Schedules) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(s.StringSlice(), ",")), nil
+}
+
 func (s Schedules) Next(date time.Time, sans ...TimeSlot) (time.Time, error) {
 	return Next(date, s, sans...)
 }
@@ -410,26 +511,53 @@ func (s *Schedules) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValu
 	return nil
 }
 
-// UnmarshalJSON implements json.Unmarshaler
+// UnmarshalJSON implements json.Unmarshaler.  Each Schedule may be either a
+// compact string or structured; see Schedule.UnmarshalJSON
 func (s *Schedules) UnmarshalJSON(data []byte) error {
-	var items []string
+	var items []Schedule
 	if err := json.Unmarshal(data, &items); err != nil {
 		return fmt.Errorf("unable to unmarshal Schedules: %w", err)
 	}
 
+	if len(items) == 0 {
+		*s = nil
+		return nil
+	}
+
+	*s = Schedules(items)
+
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for comma separated
+// Schedules; see MarshalText.  Each Schedule is validated
+func (s *Schedules) UnmarshalText(text []byte) error {
 	var ss Schedules
-	for _, item := range items {
-		ss = append(ss, Schedule(item))
+	for _, item := range strings.Split(string(text), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		v := Schedule(item)
+		if err := v.validate(); err != nil {
+			return err
+		}
+		ss = append(ss, v)
 	}
 
 	*s = ss
-
 	return nil
 }
 
 func buildSchedule(dateFrom string, dateTo string, from Time, to Time, weekdays []time.Weekday) []byte {
+	version := byte('1')
+	if isAnnual(dateFrom) || isAnnual(dateTo) {
+		version = '3'
+	}
+
 	buffer := make([]byte, 0, 64)
-	buffer = append(buffer, '1') // version
+	buffer = append(buffer, version)
 	buffer = append(buffer, ':')
 	buffer = append(buffer, dateFrom...)
 	buffer = append(buffer, ':')
@@ -448,13 +576,25 @@ func buildSchedule(dateFrom string, dateTo string, from Time, to Time, weekdays
 	return buffer
 }
 
+// slotSchedule returns a Schedule for the TimeSlot; overnight TimeSlots are
+// encoded with a to time earlier than from
+func slotSchedule(dateFrom, dateTo string, slot TimeSlot, weekdays ...time.Weekday) Schedule {
+	to := slot.To
+	if to > EndOfDay {
+		to -= EndOfDay
+	}
+	return Schedule(buildSchedule(dateFrom, dateTo, slot.From, to, weekdays))
+}
+
 func alignMidnight(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return Midnight.Align(t)
 }
 
 // After returns the set of TimeSlots that occur on the date provided AND
 // after the time provided.  Results will be unioned and sorted
 func After(date time.Time, ss ...Schedule) ([]TimeSlot, error) {
+	date = localize(date, ss...)
+
 	timeSlots, err := TimeSlots(date, ss...)
 	if err != nil {
 		return nil, err
@@ -500,6 +640,41 @@ func ContainsWeekday(weekday time.Weekday, ss ...Schedule) bool {
 	return false
 }
 
+// IsOpen returns true if the schedules provided are open at the requested time
+// of day, accounting for overrides, exclusions and overnight hours.  IsOpen
+// returns false wherever TimeSlots returns an error e.g. for an unknown zone
+func IsOpen(t time.Time, ss ...Schedule) bool {
+	t = localize(t, ss...)
+
+	timeSlots, err := TimeSlots(t, ss...)
+	if err != nil {
+		return false
+	}
+
+	want := NewTimeFromDate(t)
+	for _, timeSlot := range timeSlots {
+		if want >= timeSlot.From && want < timeSlot.To {
+			return true
+		}
+	}
+	return false
+}
+
+// Annual returns the date, --MM-DD, that recurs on month and day every year.
+// Annual dates may be used in place of YYYY-MM-DD dates e.g.
+//
+//	ExcludeDateRange(Annual(time.December, 24), Annual(time.January, 2))
+//
+// A range whose from date is later in the year than its to date wraps the
+// year boundary
+func Annual(month time.Month, day int) string {
+	return fmt.Sprintf("--%02d-%02d", int(month), day)
+}
+
+func isAnnual(date string) bool {
+	return strings.HasPrefix(date, "--")
+}
+
 // DateRange returns a new Schedule from a date range.  Useful for special holiday
 // hours.
 func DateRange(dateFrom, dateTo string, from, to Time, weekdays ...time.Weekday) Schedule {
@@ -510,72 +685,21 @@ func DateRange(dateFrom, dateTo string, from, to Time, weekdays ...time.Weekday)
 // ExcludeDateRange defines an excluded Schedule for a date range.  Useful for
 // holiday closed hours
 func ExcludeDateRange(dateFrom, dateTo string, weekdays ...time.Weekday) Schedule {
-	buffer := buildSchedule(dateFrom, dateTo, 0, 0, weekdays)
+	return ExcludeTimeRange(dateFrom, dateTo, 0, 0, weekdays...)
+}
+
+// ExcludeTimeRange defines an excluded Schedule for the window from - to on
+// each date in the date range.  The rest of the day remains open.  Useful for
+// staff meetings and other partial day closures
+func ExcludeTimeRange(dateFrom, dateTo string, from, to Time, weekdays ...time.Weekday) Schedule {
+	buffer := buildSchedule(dateFrom, dateTo, from, to, weekdays)
 	buffer = append(buffer, exclude...)
 	return Schedule(buffer)
 }
 
-// Next returns the next time available from the Schedule provided with at least
-// buffer duration remaining in the schedule
+// Next returns the next time available from the Schedules provided within
+// the next 7 days.  The time returned is in the location of the Schedules, if
+// any.  See NextWith for additional options
 func Next(date time.Time, ss Schedules, sans ...TimeSlot) (time.Time, error) {
-	const daysOut = 7
-	for i := 0; i < daysOut; i++ {
-		d := date.AddDate(0, 0, i)
-		timeSlots, err := After(d, ss...)
-		if err != nil {
-			return time.Time{}, err
-		}
-
-		if len(sans) > 0 {
-			timeSlots = SubAll(timeSlots, sans)
-		}
-
-		for _, timeSlot := range timeSlots {
-			return timeSlot.From.Align(d), nil
-		}
-
-		if i == 0 {
-			date = alignMidnight(date)
-		}
-	}
-
-	return time.Time{}, fmt.Errorf("no time matches in next %v days", daysOut)
-}
-
-// TimeSlots returns the timeslots for the date
-func TimeSlots(date time.Time, ss ...Schedule) ([]TimeSlot, error) {
-	var regular, holiday []TimeSlot
-	for _, s := range ss {
-		if !s.Contains(date) {
-			continue
-		}
-		if s.IsExclude() {
-			return nil, nil // excluded date
-		}
-
-		timeSlot, err := s.TimeSlot()
-		if err != nil {
-			return nil, err
-		}
-
-		if s.HasDateRange() {
-			holiday = append(holiday, timeSlot)
-			continue
-		}
-
-		regular = append(regular, timeSlot)
-	}
-
-	slots := holiday
-	if len(slots) == 0 {
-		slots = regular
-	}
-
-	slots = Union(slots...)
-
-	sort.Slice(slots, func(i, j int) bool {
-		return slots[i].From < slots[j].From
-	})
-
-	return slots, nil
+	return NextWith(date, ss, WithSans(sans...))
 }
@@ -2,11 +2,14 @@ package schedule
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/tj/assert"
 )
@@ -151,6 +154,41 @@ func TestSchedule_JSON(t *testing.T) {
 	assert.Equal(t, string(want), string(got))
 }
 
+func TestSchedule_Text(t *testing.T) {
+	want := New(900, 1700, time.Monday, time.Tuesday)
+
+	text, err := want.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "1:::0900:1700:MoTu:", string(text))
+
+	var got Schedule
+	err = got.UnmarshalText(text)
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+
+	// the text buffer may be reused by the decoder
+	text[0] = '2'
+	assert.Equal(t, want, got)
+
+	t.Run("empty", func(t *testing.T) {
+		text, err := Schedule(nil).MarshalText()
+		assert.Nil(t, err)
+		assert.Equal(t, "", string(text))
+
+		got := New(900, 1700)
+		err = got.UnmarshalText(text)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var got Schedule
+		err := got.UnmarshalText([]byte("1:::0900:2500::"))
+		assert.NotNil(t, err)
+		assert.Nil(t, got)
+	})
+}
+
 func TestOrder(t *testing.T) {
 	v := []string{
 		"1:::",
@@ -588,7 +626,409 @@ func TestSchedules_UnmarshalJSON(t *testing.T) {
 	assert.Equal(t, got, want)
 }
 
+func TestSchedules_Text(t *testing.T) {
+	want := Schedules{New(800, 1200, time.Monday), ExcludeDateRange("2020-12-25", "2020-12-25")}
+
+	text, err := want.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "1:::0800:1200:Mo:,1:2020-12-25:2020-12-25:0000:0000::exclude", string(text))
+
+	var got Schedules
+	err = got.UnmarshalText(text)
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+
+	t.Run("spaces", func(t *testing.T) {
+		var got Schedules
+		err := got.UnmarshalText([]byte(" 1:::0800:1200:Mo:, 1:::1300:1700:Mo: "))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1:::0800:1200:Mo:", "1:::1300:1700:Mo:"}, got.StringSlice())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var got Schedules
+		err := got.UnmarshalText(nil)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var got Schedules
+		err := got.UnmarshalText([]byte("1:::0800:1200:Mo:,1:::0800:1200:Xy:"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		data, err := json.Marshal(struct{ Hours Schedules }{Hours: want})
+		assert.Nil(t, err)
+		assert.Equal(t, `{"Hours":["1:::0800:1200:Mo:","1:2020-12-25:2020-12-25:0000:0000::exclude"]}`, string(data))
+	})
+}
+
 func TestExclude(t *testing.T) {
 	s := ExcludeDateRange("2020-07-01", "2020-07-15")
 	assert.True(t, s.IsExclude())
 }
+
+func TestSchedules_Overnight(t *testing.T) {
+	var (
+		ss       = Schedules{New(1800, 200, time.Friday)}
+		friday   = time.Date(2020, time.July, 24, 0, 0, 0, 0, time.UTC)
+		saturday = friday.AddDate(0, 0, 1)
+	)
+
+	t.Run("time slots", func(t *testing.T) {
+		got, err := ss.TimeSlots(friday)
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(1800, 200)}, got)
+
+		got, err = ss.TimeSlots(saturday)
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(Midnight, 200)}, got)
+	})
+
+	t.Run("after", func(t *testing.T) {
+		got, err := ss.After(NewTime(23, 0).Align(friday))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(2300, 200)}, got)
+
+		got, err = ss.After(NewTime(1, 30).Align(saturday))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(130, 200)}, got)
+	})
+
+	t.Run("next", func(t *testing.T) {
+		want := NewTime(1, 30).Align(saturday)
+		got, err := ss.Next(want)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got)
+
+		got, err = ss.Next(NewTime(3, 0).Align(saturday))
+		assert.Nil(t, err)
+		assert.Equal(t, NewTime(18, 0).Align(friday.AddDate(0, 0, 7)), got)
+
+		got, err = ss.Next(NewTime(23, 0).Align(friday), NewTimeSlot(2300, 30))
+		assert.Nil(t, err)
+		assert.Equal(t, NewTime(0, 30).Align(saturday), got)
+	})
+}
+
+func TestNext_DST(t *testing.T) {
+	testCases := map[string]struct {
+		Zone     string
+		Schedule Schedule
+		Date     time.Time
+		Want     string
+	}{
+		"new york - spring forward gap": {
+			Zone:     "America/New_York",
+			Schedule: New(230, 400),
+			Date:     time.Date(2020, time.March, 8, 5, 0, 0, 0, time.UTC), // 00:00 EST
+			Want:     "2020-03-08T03:00:00-04:00",
+		},
+		"new york - fall back second occurrence": {
+			Zone:     "America/New_York",
+			Schedule: New(100, 300),
+			Date:     time.Date(2020, time.November, 1, 6, 30, 0, 0, time.UTC), // 01:30 EST
+			Want:     "2020-11-01T01:30:00-05:00",
+		},
+		"new york - next day after fall back": {
+			Zone:     "America/New_York",
+			Schedule: New(900, 1700),
+			Date:     time.Date(2020, time.October, 31, 22, 0, 0, 0, time.UTC), // 18:00 EDT
+			Want:     "2020-11-01T09:00:00-05:00",
+		},
+		"london - spring forward gap": {
+			Zone:     "Europe/London",
+			Schedule: New(130, 300),
+			Date:     time.Date(2020, time.March, 29, 0, 0, 0, 0, time.UTC),
+			Want:     "2020-03-29T02:00:00+01:00",
+		},
+		"london - fall back": {
+			Zone:     "Europe/London",
+			Schedule: New(100, 300),
+			Date:     time.Date(2020, time.October, 24, 23, 0, 0, 0, time.UTC), // 00:00 BST
+			Want:     "2020-10-25T01:00:00+01:00",
+		},
+		"lord howe - spring forward gap": {
+			Zone:     "Australia/Lord_Howe",
+			Schedule: New(215, 400),
+			Date:     time.Date(2020, time.October, 3, 13, 30, 0, 0, time.UTC), // 00:00 +10:30
+			Want:     "2020-10-04T02:30:00+11:00",
+		},
+		"lord howe - fall back": {
+			Zone:     "Australia/Lord_Howe",
+			Schedule: New(145, 300),
+			Date:     time.Date(2020, time.April, 4, 13, 0, 0, 0, time.UTC), // 00:00 +11:00
+			Want:     "2020-04-05T01:45:00+11:00",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			loc, err := time.LoadLocation(tc.Zone)
+			assert.Nil(t, err)
+
+			ss := Schedules{tc.Schedule}.In(loc)
+			got, err := ss.Next(tc.Date)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, got.Format(time.RFC3339))
+			assert.False(t, got.Before(tc.Date))
+		})
+	}
+}
+
+func TestAfter_DST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	// 01:30 EST, after the fall back transition
+	date := time.Date(2020, time.November, 1, 6, 30, 0, 0, time.UTC)
+	got, err := After(date, New(0, 400).In(loc))
+	assert.Nil(t, err)
+	assert.Equal(t, []TimeSlot{NewTimeSlot(130, 400)}, got)
+
+	// elapsed time on the transition dates
+	var (
+		springForward = time.Date(2020, time.March, 8, 0, 0, 0, 0, loc)
+		fallBack      = time.Date(2020, time.November, 1, 0, 0, 0, 0, loc)
+	)
+	assert.Equal(t, 4*time.Hour, NewTimeSlot(0, 400).Duration())
+	assert.Equal(t, 3*time.Hour, NewTimeSlot(0, 400).DurationOn(springForward))
+	assert.Equal(t, 4*time.Hour, NewTimeSlot(0, 400).DurationOn(fallBack))
+	assert.Equal(t, 30*time.Minute, NewTimeSlot(100, 130).DurationOn(fallBack))
+	assert.Equal(t, time.Hour, NewTimeSlot(130, 230).DurationOn(fallBack))
+	assert.Equal(t, 4*time.Hour, NewTimeSlot(0, 400).DurationOn(springForward.AddDate(0, 0, 1)))
+	assert.Equal(t, 4*time.Hour, NewTimeSlot(2200, 300).DurationOn(springForward.AddDate(0, 0, -1)))
+
+	// Hours on the transition dates total the same
+	for date, want := range map[time.Time]time.Duration{springForward: 3 * time.Hour, fallBack: 4 * time.Hour} {
+		slots, ok := Hours(date, New(0, 400).In(loc))
+		assert.True(t, ok)
+
+		var total time.Duration
+		for _, slot := range slots {
+			total += slot.DurationOn(date)
+		}
+		assert.Equal(t, want, total)
+	}
+
+	// a 4h opening is not available on the spring forward date
+	next, err := NextWith(springForward, Schedules{New(0, 400).In(loc)}, WithDuration(4*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, springForward.AddDate(0, 0, 1), next)
+}
+
+func TestSchedule_UnmarshalDynamoDBAttributeValue(t *testing.T) {
+	var got Schedule
+	err := got.UnmarshalDynamoDBAttributeValue(&dynamodb.AttributeValue{S: aws.String("1:x:y:abc:def:Zz:")})
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, indexDateFrom, pe.Field)
+}
+
+func TestExcludeTimeRange(t *testing.T) {
+	var (
+		date    = time.Date(2020, time.July, 20, 0, 0, 0, 0, time.UTC)
+		dateStr = date.Format(DateLayout)
+		meeting = ExcludeTimeRange(dateStr, dateStr, 1200, 1400)
+		ss      = Schedules{New(800, 1800), meeting}
+	)
+
+	assert.Equal(t, "1:2020-07-20:2020-07-20:1200:1400::exclude", meeting.String())
+	assert.True(t, meeting.IsExclude())
+	assert.False(t, meeting.ExcludesAllDay())
+	assert.True(t, ExcludeDateRange(dateStr, dateStr).ExcludesAllDay())
+	assert.False(t, New(800, 1800).ExcludesAllDay())
+
+	t.Run("time slots", func(t *testing.T) {
+		got, err := ss.TimeSlots(date)
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(800, 1200), NewTimeSlot(1400, 1800)}, got)
+
+		got, err = ss.TimeSlots(date.AddDate(0, 0, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(800, 1800)}, got)
+	})
+
+	t.Run("after", func(t *testing.T) {
+		got, err := ss.After(NewTime(11, 0).Align(date))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(1100, 1200), NewTimeSlot(1400, 1800)}, got)
+	})
+
+	t.Run("next", func(t *testing.T) {
+		got, err := ss.Next(NewTime(12, 30).Align(date))
+		assert.Nil(t, err)
+		assert.Equal(t, NewTime(14, 0).Align(date), got)
+	})
+
+	t.Run("hours", func(t *testing.T) {
+		got, ok := Hours(date, ss...)
+		assert.True(t, ok)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(800, 1200), NewTimeSlot(1400, 1800)}, got)
+	})
+
+	t.Run("availability", func(t *testing.T) {
+		got := Availability(date, ss, []TimeSlot{NewTimeSlot(900, 1000)})
+		assert.Equal(t, []TimeSlot{NewTimeSlot(800, 900), NewTimeSlot(1000, 1200), NewTimeSlot(1400, 1800)}, got)
+	})
+
+	t.Run("contains time", func(t *testing.T) {
+		assert.True(t, ss.ContainsTime(NewTime(9, 0).Align(date)))
+		assert.False(t, ss.ContainsTime(NewTime(12, 30).Align(date)))
+	})
+
+	t.Run("overnight window", func(t *testing.T) {
+		ss := Schedules{New(1800, 400), ExcludeTimeRange(dateStr, dateStr, 2300, 100)}
+
+		got, err := ss.TimeSlots(date)
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(Midnight, 400), NewTimeSlot(1800, 2300), {From: 2500, To: 2800}}, got)
+
+		got, err = ss.TimeSlots(date.AddDate(0, 0, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(100, 400), NewTimeSlot(1800, 400)}, got)
+	})
+}
+
+func TestIsOpen(t *testing.T) {
+	var (
+		date     = time.Date(2020, time.July, 24, 0, 0, 0, 0, time.UTC) // Friday
+		today    = date.Format(DateLayout)
+		saturday = date.AddDate(0, 0, 1)
+	)
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		Time      time.Time
+		Want      bool
+	}{
+		"before open": {
+			Schedules: Schedules{New(800, 1800)},
+			Time:      NewTime(3, 0).Align(date),
+			Want:      false,
+		},
+		"open": {
+			Schedules: Schedules{New(800, 1800)},
+			Time:      NewTime(8, 0).Align(date),
+			Want:      true,
+		},
+		"closing time": {
+			Schedules: Schedules{New(800, 1800)},
+			Time:      NewTime(18, 0).Align(date),
+			Want:      false,
+		},
+		"override": {
+			Schedules: Schedules{New(800, 1800), DateRange(today, today, 800, 1200)},
+			Time:      NewTime(13, 0).Align(date),
+			Want:      false,
+		},
+		"excluded": {
+			Schedules: Schedules{New(800, 1800), ExcludeDateRange(today, today)},
+			Time:      NewTime(9, 0).Align(date),
+			Want:      false,
+		},
+		"excluded window": {
+			Schedules: Schedules{New(800, 1800), ExcludeTimeRange(today, today, 1200, 1400)},
+			Time:      NewTime(13, 0).Align(date),
+			Want:      false,
+		},
+		"overnight": {
+			Schedules: Schedules{New(1800, 200, time.Friday)},
+			Time:      NewTime(1, 30).Align(saturday),
+			Want:      true,
+		},
+		"overnight - not before": {
+			Schedules: Schedules{New(1800, 200, time.Friday)},
+			Time:      NewTime(1, 30).Align(date),
+			Want:      false,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			assert.Equal(t, tc.Want, tc.Schedules.IsOpen(tc.Time))
+			assert.Equal(t, tc.Want, tc.Schedules.ContainsTime(tc.Time))
+		})
+	}
+}
+
+func TestAnnual(t *testing.T) {
+	var (
+		christmas = ExcludeDateRange(Annual(time.December, 25), Annual(time.December, 25))
+		holidays  = ExcludeDateRange(Annual(time.December, 24), Annual(time.January, 2))
+		weekdays  = New(900, 1700)
+		date      = func(s string) time.Time {
+			v, err := time.Parse(DateLayout, s)
+			assert.Nil(t, err)
+			return v
+		}
+	)
+
+	assert.Equal(t, "--12-25", Annual(time.December, 25))
+	assert.Equal(t, "3:--12-24:--01-02:0000:0000::exclude", holidays.String())
+
+	from, ok := holidays.DateFrom()
+	assert.True(t, ok)
+	assert.Equal(t, "--12-24", from)
+	to, ok := holidays.DateTo()
+	assert.True(t, ok)
+	assert.Equal(t, "--01-02", to)
+
+	t.Run("contains", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Date     string
+			Want     bool
+		}{
+			"christmas":             {Schedule: christmas, Date: "2020-12-25", Want: true},
+			"christmas - next year": {Schedule: christmas, Date: "2031-12-25", Want: true},
+			"christmas - eve":       {Schedule: christmas, Date: "2020-12-24", Want: false},
+			"wrap - start":          {Schedule: holidays, Date: "2020-12-24", Want: true},
+			"wrap - new year":       {Schedule: holidays, Date: "2021-01-01", Want: true},
+			"wrap - end":            {Schedule: holidays, Date: "2021-01-02", Want: true},
+			"wrap - after":          {Schedule: holidays, Date: "2021-01-03", Want: false},
+			"wrap - before":         {Schedule: holidays, Date: "2020-12-23", Want: false},
+			"wrap - summer":         {Schedule: holidays, Date: "2020-07-01", Want: false},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				assert.Equal(t, tc.Want, tc.Schedule.Contains(date(tc.Date)))
+			})
+		}
+	})
+
+	t.Run("time slots", func(t *testing.T) {
+		got, err := TimeSlots(date("2025-12-25"), weekdays, christmas)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+
+		got, err = TimeSlots(date("2025-12-24"), weekdays, DateRange(Annual(time.December, 24), Annual(time.December, 24), 900, 1200))
+		assert.Nil(t, err)
+		assert.Equal(t, []TimeSlot{NewTimeSlot(900, 1200)}, got)
+	})
+
+	t.Run("add date", func(t *testing.T) {
+		got := AddDate(date("2025-12-23"), 1, holidays)
+		assert.Equal(t, "2026-01-03", got.Format(DateLayout))
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		_, err := Parse(holidays.String())
+		assert.Nil(t, err)
+
+		_, err = Parse("1:--12-24:--01-02:0000:0000::exclude")
+		assert.EqualError(t, err, `invalid Schedule, "1:--12-24:--01-02:0000:0000::exclude": date-from: annual dates require version 3`)
+
+		_, err = Parse("3:--12-24:2021-01-02:0000:0000::exclude")
+		assert.EqualError(t, err, `invalid Schedule, "3:--12-24:2021-01-02:0000:0000::exclude": date-to: date-from and date-to must both be annual`)
+
+		_, err = Parse("3:--02-30:--03-01:0000:0000::exclude")
+		assert.EqualError(t, err, `invalid Schedule, "3:--02-30:--03-01:0000:0000::exclude": date-from: invalid annual date, --02-30`)
+
+		_, err = Parse("3:--02-29:--02-29:0000:0000::exclude")
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,268 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OpeningHoursSpecification is a schema.org OpeningHoursSpecification as
+// published in JSON-LD.  Special hours and closures are scoped to dates by
+// ValidFrom and ValidThrough; closures open and close at 00:00
+type OpeningHoursSpecification struct {
+	Type         string    `json:"@type"`
+	DayOfWeek    DayOfWeek `json:"dayOfWeek,omitempty"`
+	Opens        string    `json:"opens"`
+	Closes       string    `json:"closes"`
+	ValidFrom    string    `json:"validFrom,omitempty"`
+	ValidThrough string    `json:"validThrough,omitempty"`
+}
+
+// DayOfWeek holds schema.org day names e.g. Monday.  A single day may be
+// unmarshaled from a string and days may be written as schema.org URLs e.g.
+// https://schema.org/Monday
+type DayOfWeek []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *DayOfWeek) UnmarshalJSON(data []byte) error {
+	var day string
+	if err := json.Unmarshal(data, &day); err == nil {
+		*d = DayOfWeek{day}
+		return nil
+	}
+
+	var days []string
+	if err := json.Unmarshal(data, &days); err != nil {
+		return fmt.Errorf("unable to unmarshal dayOfWeek: %w", err)
+	}
+	*d = days
+	return nil
+}
+
+// SchemaOrg encodes Schedules as a JSON-LD array of schema.org
+// OpeningHoursSpecification e.g.
+//
+//	json.Marshal(SchemaOrg(ss))
+type SchemaOrg Schedules
+
+// MarshalJSON implements json.Marshaler
+func (s SchemaOrg) MarshalJSON() ([]byte, error) {
+	specs, err := Schedules(s).OpeningHoursSpecification()
+	if err != nil {
+		return nil, err
+	}
+	if specs == nil {
+		specs = []OpeningHoursSpecification{}
+	}
+	return json.Marshal(specs)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *SchemaOrg) UnmarshalJSON(data []byte) error {
+	var specs []OpeningHoursSpecification
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return fmt.Errorf("unable to unmarshal OpeningHoursSpecification: %w", err)
+	}
+
+	ss, err := FromOpeningHoursSpecification(specs...)
+	if err != nil {
+		return err
+	}
+
+	*s = SchemaOrg(ss)
+	return nil
+}
+
+// OpeningHoursSpecification converts the Schedules to schema.org
+// OpeningHoursSpecification.  Regular hours list their weekdays, DateRange
+// hours and ExcludeDateRange closures are scoped by validFrom and
+// validThrough.  Hours open through midnight close at 23:59 as recommended
+// for JSON-LD.  Zones, annual dates, recurrences and partial day excludes
+// cannot be represented
+func (s Schedules) OpeningHoursSpecification() ([]OpeningHoursSpecification, error) {
+	var specs []OpeningHoursSpecification
+	for _, item := range s {
+		spec, err := schemaOrgSpec(item)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode %v as OpeningHoursSpecification: %w", item, err)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+func schemaOrgSpec(s Schedule) (OpeningHoursSpecification, error) {
+	if _, ok := s.Recurrence(); ok {
+		return OpeningHoursSpecification{}, fmt.Errorf("recurrences are not supported")
+	}
+	if _, ok := s.Zone(); ok {
+		return OpeningHoursSpecification{}, fmt.Errorf("zoned schedules are not supported")
+	}
+
+	slot, err := s.TimeSlot()
+	if err != nil {
+		return OpeningHoursSpecification{}, err
+	}
+
+	spec := OpeningHoursSpecification{
+		Type:   "OpeningHoursSpecification",
+		Opens:  slot.From.String(),
+		Closes: schemaOrgTime(slot.To),
+	}
+
+	if s.HasDateRange() {
+		from, _ := s.DateFrom()
+		to, _ := s.DateTo()
+		if isAnnual(from) {
+			return OpeningHoursSpecification{}, fmt.Errorf("annual dates are not supported")
+		}
+		spec.ValidFrom = from
+		if to != maxDate {
+			spec.ValidThrough = to
+		}
+	}
+
+	switch {
+	case s.IsExclude() && !s.ExcludesAllDay():
+		return OpeningHoursSpecification{}, fmt.Errorf("partial day excludes are not supported")
+	case s.IsExclude() && !s.HasDateRange():
+		return OpeningHoursSpecification{}, fmt.Errorf("excludes require a date range")
+	case s.IsExclude():
+		spec.Opens, spec.Closes = Midnight.String(), Midnight.String()
+	}
+
+	weekdays := s.Weekdays()
+	if len(weekdays) == 0 && !s.HasDateRange() {
+		weekdays = mondayFirst
+	}
+	for _, w := range weekdays {
+		spec.DayOfWeek = append(spec.DayOfWeek, w.String())
+	}
+
+	return spec, nil
+}
+
+// schemaOrgTime formats the closing time; overnight hours close on the
+// following day
+func schemaOrgTime(t Time) string {
+	switch {
+	case t == EndOfDay:
+		return "23:59"
+	case t > EndOfDay:
+		return (t - EndOfDay).String()
+	default:
+		return t.String()
+	}
+}
+
+// FromOpeningHoursSpecification converts schema.org OpeningHoursSpecification
+// to Schedules; see Schedules.OpeningHoursSpecification.  Specifications that
+// open and close at 00:00 are closures.  Hours closing at 23:59 or 24:00 are
+// open through midnight.  A specification without dayOfWeek applies to every
+// day
+func FromOpeningHoursSpecification(specs ...OpeningHoursSpecification) (Schedules, error) {
+	var ss Schedules
+	for i, spec := range specs {
+		s, ok, err := spec.schedule()
+		if err != nil {
+			return nil, fmt.Errorf("invalid OpeningHoursSpecification %v: %w", i, err)
+		}
+		if ok {
+			ss = append(ss, s)
+		}
+	}
+	return ss, nil
+}
+
+// schedule returns the Schedule for the specification; returns false for
+// weekdays without a date range that are closed
+func (spec OpeningHoursSpecification) schedule() (Schedule, bool, error) {
+	opens, err := schemaOrgParseTime(spec.Opens)
+	if err != nil {
+		return nil, false, err
+	}
+	closes, err := schemaOrgParseTime(spec.Closes)
+	switch {
+	case spec.Closes == "24:00" || spec.Closes == "24:00:00":
+		closes = EndOfDay
+	case err != nil:
+		return nil, false, err
+	case closes == NewTime(23, 59):
+		closes = EndOfDay
+	}
+
+	var weekdays []time.Weekday
+	for _, day := range spec.DayOfWeek {
+		w, ok := schemaOrgWeekday(day)
+		if !ok {
+			return nil, false, fmt.Errorf("unsupported dayOfWeek, %v", day)
+		}
+		weekdays = append(weekdays, w)
+	}
+
+	from, ok := schemaOrgDate(spec.ValidFrom)
+	if !ok {
+		return nil, false, fmt.Errorf("invalid validFrom, %v", spec.ValidFrom)
+	}
+	through, ok := schemaOrgDate(spec.ValidThrough)
+	if !ok {
+		return nil, false, fmt.Errorf("invalid validThrough, %v", spec.ValidThrough)
+	}
+
+	switch {
+	case from == "" && through != "":
+		return nil, false, fmt.Errorf("validThrough requires validFrom")
+	case from != "" && through == "":
+		through = maxDate
+	}
+
+	closed := opens == Midnight && closes == Midnight
+	switch {
+	case closed && from == "":
+		return nil, false, nil // closed on weekdays; the absence of hours
+	case closed:
+		return ExcludeDateRange(from, through, weekdays...), true, nil
+	case len(weekdays) == len(mondayFirst):
+		weekdays = nil
+	}
+
+	return Schedule(buildSchedule(from, through, opens, closes, weekdays)), true, nil
+}
+
+// schemaOrgParseTime parses hh:mm or hh:mm:ss
+func schemaOrgParseTime(v string) (Time, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return NewTimeFromDate(t), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time, %v", v)
+}
+
+// schemaOrgDate returns the YYYY-MM-DD date of a Date or DateTime
+func schemaOrgDate(v string) (string, bool) {
+	if v == "" {
+		return "", true
+	}
+	if len(v) > len(DateLayout) && v[len(DateLayout)] == 'T' {
+		v = v[:len(DateLayout)]
+	}
+	if _, err := time.Parse(DateLayout, v); err != nil {
+		return "", false
+	}
+	return v, true
+}
+
+// schemaOrgWeekday parses a day name e.g. Monday or https://schema.org/Monday
+func schemaOrgWeekday(day string) (time.Weekday, bool) {
+	if i := strings.LastIndex(day, "/"); i >= 0 {
+		day = day[i+1:]
+	}
+	for _, w := range mondayFirst {
+		if strings.EqualFold(day, w.String()) {
+			return w, true
+		}
+	}
+	return 0, false
+}
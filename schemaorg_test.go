@@ -0,0 +1,206 @@
+package schedule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestSchemaOrg_MarshalJSON(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	testCases := map[string]struct {
+		Schedules Schedules
+		Want      string
+	}{
+		"weekdays": {
+			Schedules: Schedules{New(900, 1700, weekdays...)},
+			Want:      `[{"@type":"OpeningHoursSpecification","dayOfWeek":["Monday","Tuesday","Wednesday","Thursday","Friday"],"opens":"09:00","closes":"17:00"}]`,
+		},
+		"every day": {
+			Schedules: Schedules{New(Midnight, EndOfDay)},
+			Want:      `[{"@type":"OpeningHoursSpecification","dayOfWeek":["Monday","Tuesday","Wednesday","Thursday","Friday","Saturday","Sunday"],"opens":"00:00","closes":"23:59"}]`,
+		},
+		"overnight": {
+			Schedules: Schedules{New(2200, 200, time.Friday)},
+			Want:      `[{"@type":"OpeningHoursSpecification","dayOfWeek":["Friday"],"opens":"22:00","closes":"02:00"}]`,
+		},
+		"special hours": {
+			Schedules: Schedules{DateRange("2020-12-24", "2020-12-24", 1000, 1400)},
+			Want:      `[{"@type":"OpeningHoursSpecification","opens":"10:00","closes":"14:00","validFrom":"2020-12-24","validThrough":"2020-12-24"}]`,
+		},
+		"closure": {
+			Schedules: Schedules{ExcludeDateRange("2020-12-25", "2020-12-26")},
+			Want:      `[{"@type":"OpeningHoursSpecification","opens":"00:00","closes":"00:00","validFrom":"2020-12-25","validThrough":"2020-12-26"}]`,
+		},
+		"empty": {
+			Schedules: nil,
+			Want:      `[]`,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			data, err := json.Marshal(SchemaOrg(tc.Schedules))
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, string(data))
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		testCases := map[string]struct {
+			Schedule Schedule
+			Want     string
+		}{
+			"recurrence": {
+				Schedule: New(900, 1700).Recur(EveryWeeks(2, "2020-01-04")),
+				Want:     "unable to encode 4:::0900:1700::::every=2w/2020-01-04 as OpeningHoursSpecification: recurrences are not supported",
+			},
+			"zone": {
+				Schedule: New(900, 1700).In(time.UTC),
+				Want:     "unable to encode 2:::0900:1700:::UTC as OpeningHoursSpecification: zoned schedules are not supported",
+			},
+			"annual": {
+				Schedule: ExcludeDateRange(Annual(time.December, 25), Annual(time.December, 25)),
+				Want:     "unable to encode 3:--12-25:--12-25:0000:0000::exclude as OpeningHoursSpecification: annual dates are not supported",
+			},
+			"partial exclude": {
+				Schedule: ExcludeTimeRange("2020-01-06", "2020-01-06", 1200, 1300),
+				Want:     "unable to encode 1:2020-01-06:2020-01-06:1200:1300::exclude as OpeningHoursSpecification: partial day excludes are not supported",
+			},
+			"weekday exclude": {
+				Schedule: ExcludeDateRange("", "", time.Sunday),
+				Want:     "unable to encode 1:::0000:0000:Su:exclude as OpeningHoursSpecification: excludes require a date range",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				_, err := Schedules{tc.Schedule}.OpeningHoursSpecification()
+				assert.EqualError(t, err, tc.Want)
+			})
+		}
+	})
+}
+
+func TestSchemaOrg_UnmarshalJSON(t *testing.T) {
+	testCases := map[string]struct {
+		Input string
+		Want  []string
+	}{
+		"weekdays": {
+			Input: `[{"@type":"OpeningHoursSpecification","dayOfWeek":["Monday","Tuesday"],"opens":"09:00","closes":"17:00"}]`,
+			Want:  []string{"1:::0900:1700:MoTu:"},
+		},
+		"single day": {
+			Input: `[{"@type":"OpeningHoursSpecification","dayOfWeek":"Saturday","opens":"10:00:00","closes":"14:00:00"}]`,
+			Want:  []string{"1:::1000:1400:Sa:"},
+		},
+		"day urls": {
+			Input: `[{"@type":"OpeningHoursSpecification","dayOfWeek":["https://schema.org/Sunday","http://schema.org/Monday"],"opens":"10:00","closes":"14:00"}]`,
+			Want:  []string{"1:::1000:1400:SuMo:"},
+		},
+		"every day": {
+			Input: `[{"@type":"OpeningHoursSpecification","dayOfWeek":["Monday","Tuesday","Wednesday","Thursday","Friday","Saturday","Sunday"],"opens":"00:00","closes":"23:59"}]`,
+			Want:  []string{"1:::0000:2400::"},
+		},
+		"until midnight": {
+			Input: `[{"@type":"OpeningHoursSpecification","dayOfWeek":"Friday","opens":"18:00","closes":"24:00"}]`,
+			Want:  []string{"1:::1800:2400:Fr:"},
+		},
+		"special hours": {
+			Input: `[{"@type":"OpeningHoursSpecification","opens":"10:00","closes":"14:00","validFrom":"2020-12-24T00:00:00-05:00","validThrough":"2020-12-24"}]`,
+			Want:  []string{"1:2020-12-24:2020-12-24:1000:1400::"},
+		},
+		"closure": {
+			Input: `[{"@type":"OpeningHoursSpecification","opens":"00:00","closes":"00:00","validFrom":"2020-12-25","validThrough":"2020-12-26"}]`,
+			Want:  []string{"1:2020-12-25:2020-12-26:0000:0000::exclude"},
+		},
+		"open ended": {
+			Input: `[{"@type":"OpeningHoursSpecification","dayOfWeek":"Monday","opens":"08:00","closes":"12:00","validFrom":"2021-01-04"}]`,
+			Want:  []string{"1:2021-01-04:9999-12-31:0800:1200:Mo:"},
+		},
+		"closed weekday": {
+			Input: `[{"@type":"OpeningHoursSpecification","dayOfWeek":"Sunday","opens":"00:00","closes":"00:00"}]`,
+			Want:  nil,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			var got SchemaOrg
+			err := json.Unmarshal([]byte(tc.Input), &got)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, Schedules(got).StringSlice())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := map[string]struct {
+			Input string
+			Want  string
+		}{
+			"time": {
+				Input: `[{"dayOfWeek":"Monday","opens":"9am","closes":"17:00"}]`,
+				Want:  "invalid OpeningHoursSpecification 0: invalid time, 9am",
+			},
+			"day": {
+				Input: `[{"dayOfWeek":"PublicHolidays","opens":"09:00","closes":"17:00"}]`,
+				Want:  "invalid OpeningHoursSpecification 0: unsupported dayOfWeek, PublicHolidays",
+			},
+			"date": {
+				Input: `[{"opens":"09:00","closes":"17:00","validFrom":"12/24/2020"}]`,
+				Want:  "invalid OpeningHoursSpecification 0: invalid validFrom, 12/24/2020",
+			},
+			"through without from": {
+				Input: `[{"opens":"09:00","closes":"17:00","validThrough":"2020-12-24"}]`,
+				Want:  "invalid OpeningHoursSpecification 0: validThrough requires validFrom",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				var got SchemaOrg
+				err := json.Unmarshal([]byte(tc.Input), &got)
+				assert.EqualError(t, err, tc.Want)
+			})
+		}
+	})
+}
+
+func TestSchemaOrg_RoundTrip(t *testing.T) {
+	ss := Schedules{
+		New(800, 1800, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday),
+		New(900, 1300, time.Saturday),
+		New(2200, 200, time.Saturday),
+		New(1800, EndOfDay, time.Sunday),
+		DateRange("2020-12-24", "2020-12-24", 1000, 1400),
+		DateRange("2020-12-28", "2020-12-31", 1000, 1200, time.Monday, time.Tuesday),
+		ExcludeDateRange("2020-12-25", "2020-12-26"),
+	}
+
+	data, err := json.Marshal(SchemaOrg(ss))
+	assert.Nil(t, err)
+
+	var got SchemaOrg
+	err = json.Unmarshal(data, &got)
+	assert.Nil(t, err)
+	assert.Equal(t, ss.StringSlice(), Schedules(got).StringSlice())
+}
+
+func TestSchemaOrg_RoundTripAllDay(t *testing.T) {
+	ss := Schedules{
+		New(Midnight, EndOfDay),
+		DateRange("2020-12-31", "2020-12-31", Midnight, EndOfDay),
+	}
+
+	data, err := json.Marshal(SchemaOrg(ss))
+	assert.Nil(t, err)
+
+	var got SchemaOrg
+	err = json.Unmarshal(data, &got)
+	assert.Nil(t, err)
+	assert.Equal(t, ss.StringSlice(), Schedules(got).StringSlice())
+}
@@ -0,0 +1,147 @@
+package schedule
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Value implements driver.Valuer; Schedule is stored as text and an empty
+// Schedule is stored as NULL
+func (s Schedule) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return s.String(), nil
+}
+
+// Scan implements sql.Scanner.  Like UnmarshalDynamoDBAttributeValue, the
+// Schedule is validated.  NULL scans as an empty Schedule, matching Value and
+// UnmarshalText
+func (s *Schedule) Scan(src interface{}) error {
+	var v Schedule
+	switch src := src.(type) {
+	case string:
+		v = Schedule(src)
+	case []byte:
+		v = Schedule(string(src))
+	case nil:
+		*s = nil
+		return nil
+	default:
+		return fmt.Errorf("unable to scan Schedule: unsupported type, %T", src)
+	}
+
+	if err := v.validate(); err != nil {
+		return err
+	}
+
+	*s = v
+	return nil
+}
+
+// Value implements driver.Valuer; Schedules are stored as a JSON array of
+// strings suitable for text and JSON columns.  Use
+// pq.Array(ss.StringSlice()) to write to a Postgres text[] column
+func (s Schedules) Value() (driver.Value, error) {
+	items := s.StringSlice()
+	if items == nil {
+		items = []string{}
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner for a JSON array or Postgres text[] column.
+// Each Schedule is validated; NULL scans as empty Schedules
+func (s *Schedules) Scan(src interface{}) error {
+	var str string
+	switch src := src.(type) {
+	case string:
+		str = src
+	case []byte:
+		str = string(src)
+	case nil:
+		*s = nil
+		return nil
+	default:
+		return fmt.Errorf("unable to scan Schedules: unsupported type, %T", src)
+	}
+
+	var items []string
+	switch str = strings.TrimSpace(str); {
+	case strings.HasPrefix(str, "{"):
+		v, err := parseTextArray(str)
+		if err != nil {
+			return fmt.Errorf("unable to scan Schedules: %w", err)
+		}
+		items = v
+	default:
+		if err := json.Unmarshal([]byte(str), &items); err != nil {
+			return fmt.Errorf("unable to scan Schedules: %w", err)
+		}
+	}
+
+	var ss Schedules
+	for _, item := range items {
+		v := Schedule(item)
+		if err := v.validate(); err != nil {
+			return err
+		}
+		ss = append(ss, v)
+	}
+
+	*s = ss
+	return nil
+}
+
+// parseTextArray parses a one dimensional Postgres text[] literal e.g.
+// {a,"b c"}
+func parseTextArray(str string) ([]string, error) {
+	if !strings.HasPrefix(str, "{") || !strings.HasSuffix(str, "}") {
+		return nil, fmt.Errorf("invalid text array, %v", str)
+	}
+	body := str[1 : len(str)-1]
+	if body == "" {
+		return nil, nil
+	}
+
+	var (
+		items     []string
+		item      strings.Builder
+		quoted    bool // within double quotes
+		wasQuoted bool // current item was quoted
+	)
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '\\' && quoted && i+1 < len(body):
+			i++
+			item.WriteByte(body[i])
+		case c == '"':
+			quoted, wasQuoted = !quoted, true
+		case c == ',' && !quoted:
+			if !wasQuoted && item.String() == "NULL" {
+				return nil, fmt.Errorf("invalid text array, NULL element")
+			}
+			items = append(items, item.String())
+			item.Reset()
+			wasQuoted = false
+		case c == '{' && !quoted:
+			return nil, fmt.Errorf("invalid text array, multiple dimensions")
+		default:
+			item.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("invalid text array, %v", str)
+	}
+	if !wasQuoted && item.String() == "NULL" {
+		return nil, fmt.Errorf("invalid text array, NULL element")
+	}
+	return append(items, item.String()), nil
+}
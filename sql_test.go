@@ -0,0 +1,140 @@
+package schedule
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+var (
+	_ driver.Valuer = Schedule(nil)
+	_ sql.Scanner   = (*Schedule)(nil)
+	_ driver.Valuer = Schedules(nil)
+	_ sql.Scanner   = (*Schedules)(nil)
+)
+
+func TestSQL(t *testing.T) {
+	var (
+		schedule  = New(800, 1800, time.Monday, time.Friday)
+		schedules = Schedules{schedule, ExcludeDateRange("2020-12-25", "2020-12-25")}
+	)
+
+	t.Run("round trip", func(t *testing.T) {
+		v, err := schedule.Value()
+		assert.Nil(t, err)
+		assert.Equal(t, "1:::0800:1800:MoFr:", v)
+
+		var got Schedule
+		err = got.Scan(v)
+		assert.Nil(t, err)
+		assert.Equal(t, schedule, got)
+
+		v, err = schedules.Value()
+		assert.Nil(t, err)
+		assert.Equal(t, `["1:::0800:1800:MoFr:","1:2020-12-25:2020-12-25:0000:0000::exclude"]`, v)
+
+		var gotSchedules Schedules
+		err = gotSchedules.Scan([]byte(v.(string)))
+		assert.Nil(t, err)
+		assert.Equal(t, schedules, gotSchedules)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		v, err := Schedule(nil).Value()
+		assert.Nil(t, err)
+		assert.Nil(t, v)
+
+		s := schedule
+		err = s.Scan(v)
+		assert.Nil(t, err)
+		assert.Nil(t, s)
+
+		ss := schedules
+		err = ss.Scan(nil)
+		assert.Nil(t, err)
+		assert.Nil(t, ss)
+	})
+
+	t.Run("empty schedules", func(t *testing.T) {
+		v, err := Schedules(nil).Value()
+		assert.Nil(t, err)
+		assert.Equal(t, "[]", v)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var s Schedule
+		assert.NotNil(t, s.Scan("1:::2500:1800::"))
+		assert.EqualError(t, s.Scan(42), "unable to scan Schedule: unsupported type, int")
+
+		var ss Schedules
+		assert.NotNil(t, ss.Scan(`["1:::0800:1800:Xy:"]`))
+	})
+}
+
+func TestSchedules_Scan(t *testing.T) {
+	testCases := map[string]struct {
+		Input interface{}
+		Want  []string
+	}{
+		"json": {
+			Input: `["1:::0800:1800:MoFr:", "2:::0900:1700:::America/New_York"]`,
+			Want:  []string{"1:::0800:1800:MoFr:", "2:::0900:1700:::America/New_York"},
+		},
+		"text array": {
+			Input: []byte(`{1:::0800:1800:MoFr:,2:::0900:1700:::America/New_York}`),
+			Want:  []string{"1:::0800:1800:MoFr:", "2:::0900:1700:::America/New_York"},
+		},
+		"quoted text array": {
+			Input: `{"1:::0800:1800:MoFr:","1:2020-12-25:2020-12-25:0000:0000::exclude"}`,
+			Want:  []string{"1:::0800:1800:MoFr:", "1:2020-12-25:2020-12-25:0000:0000::exclude"},
+		},
+		"empty text array": {
+			Input: `{}`,
+			Want:  nil,
+		},
+		"empty json": {
+			Input: `[]`,
+			Want:  nil,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			var ss Schedules
+			err := ss.Scan(tc.Input)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, ss.StringSlice())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := map[string]struct {
+			Input interface{}
+			Want  string
+		}{
+			"type": {
+				Input: 42,
+				Want:  "unable to scan Schedules: unsupported type, int",
+			},
+			"null element": {
+				Input: `{1:::0800:1800:MoFr:,NULL}`,
+				Want:  "unable to scan Schedules: invalid text array, NULL element",
+			},
+			"multiple dimensions": {
+				Input: `{{1:::0800:1800:MoFr:}}`,
+				Want:  "unable to scan Schedules: invalid text array, multiple dimensions",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				var ss Schedules
+				err := ss.Scan(tc.Input)
+				assert.EqualError(t, err, tc.Want)
+			})
+		}
+	})
+}
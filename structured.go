@@ -0,0 +1,168 @@
+package schedule
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Fields holds the fields of a Schedule and is the structured JSON form of a
+// Schedule e.g.
+//
+//	{"from":"08:00","to":"18:00","weekdays":["Mo","Tu"],"exclude":false}
+//
+// Times are hh:mm with 24:00 marking the end of the day; dates are YYYY-MM-DD
+// or annual --MM-DD
+type Fields struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Weekdays   []string `json:"weekdays,omitempty"`
+	DateFrom   string   `json:"dateFrom,omitempty"`
+	DateTo     string   `json:"dateTo,omitempty"`
+	Exclude    bool     `json:"exclude"`
+	Zone       string   `json:"zone,omitempty"`
+	Recurrence string   `json:"recurrence,omitempty"`
+}
+
+// Fields returns the fields of the Schedule
+func (s Schedule) Fields() (Fields, error) {
+	if err := s.validate(); err != nil {
+		return Fields{}, err
+	}
+
+	from, _ := s.From()
+	to, _ := s.To()
+	f := Fields{
+		From:    from.String(),
+		To:      to.String(),
+		Exclude: s.IsExclude(),
+	}
+	for _, w := range s.Weekdays() {
+		d, _ := getDayOfTheWeek(w)
+		f.Weekdays = append(f.Weekdays, d.String())
+	}
+	f.DateFrom, _ = s.DateFrom()
+	f.DateTo, _ = s.DateTo()
+	f.Zone, _ = s.Zone()
+	if r, ok := s.Recurrence(); ok {
+		f.Recurrence = r.String()
+	}
+	return f, nil
+}
+
+// Schedule returns the validated Schedule for the fields
+func (f Fields) Schedule() (Schedule, error) {
+	from, ok := fieldsTime(f.From)
+	if !ok {
+		return nil, fmt.Errorf("invalid from time, %v", f.From)
+	}
+	to, ok := fieldsTime(f.To)
+	if !ok {
+		return nil, fmt.Errorf("invalid to time, %v", f.To)
+	}
+
+	for _, w := range f.Weekdays {
+		if _, ok := DayOfTheWeek(w).Weekday(); !ok {
+			return nil, fmt.Errorf("invalid weekday, %v", w)
+		}
+	}
+
+	var version string
+	switch {
+	case f.Recurrence != "":
+		version = "4"
+	case isAnnual(f.DateFrom) || isAnnual(f.DateTo):
+		version = "3"
+	case f.Zone != "":
+		version = "2"
+	default:
+		version = "1"
+	}
+
+	var excluded string
+	if f.Exclude {
+		excluded = exclude
+	}
+
+	fields := []string{version, f.DateFrom, f.DateTo, from, to, strings.Join(f.Weekdays, ""), excluded}
+	switch {
+	case f.Recurrence != "":
+		fields = append(fields, f.Zone, f.Recurrence)
+	case f.Zone != "":
+		fields = append(fields, f.Zone)
+	}
+
+	return Parse(strings.Join(fields, ":"))
+}
+
+// fieldsTime converts hh:mm to the hhmm of the Schedule encoding
+func fieldsTime(v string) (string, bool) {
+	if len(v) != len("15:04") || v[2] != ':' {
+		return "", false
+	}
+	if v == "24:00" {
+		return "2400", true
+	}
+	return v[:2] + v[3:], true
+}
+
+// Structured encodes a Schedule as structured JSON, see Fields, rather than
+// the compact string e.g.
+//
+//	json.Marshal(Structured(s))
+//
+// Schedule.UnmarshalJSON accepts either form
+type Structured Schedule
+
+// MarshalJSON implements json.Marshaler
+func (s Structured) MarshalJSON() ([]byte, error) {
+	f, err := Schedule(s).Fields()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(f)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *Structured) UnmarshalJSON(data []byte) error {
+	return (*Schedule)(s).UnmarshalJSON(data)
+}
+
+// StructuredSchedules encodes Schedules as an array of structured JSON; see
+// Structured
+type StructuredSchedules Schedules
+
+// MarshalJSON implements json.Marshaler
+func (s StructuredSchedules) MarshalJSON() ([]byte, error) {
+	items := make([]Structured, 0, len(s))
+	for _, item := range s {
+		items = append(items, Structured(item))
+	}
+	return json.Marshal(items)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *StructuredSchedules) UnmarshalJSON(data []byte) error {
+	return (*Schedules)(s).UnmarshalJSON(data)
+}
+
+// unmarshalFields unmarshals the structured JSON form of a Schedule
+func unmarshalFields(data []byte) (Schedule, error) {
+	var f Fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal Schedule: %w", err)
+	}
+
+	v, err := f.Schedule()
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal Schedule: %w", err)
+	}
+	return v, nil
+}
+
+// isJSONObject returns true if data holds a JSON object
+func isJSONObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
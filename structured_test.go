@@ -0,0 +1,137 @@
+package schedule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestStructured(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	testCases := map[string]struct {
+		Schedule Schedule
+		Want     string
+	}{
+		"weekdays": {
+			Schedule: New(800, 1800, time.Monday, time.Tuesday),
+			Want:     `{"from":"08:00","to":"18:00","weekdays":["Mo","Tu"],"exclude":false}`,
+		},
+		"end of day": {
+			Schedule: New(1800, EndOfDay),
+			Want:     `{"from":"18:00","to":"24:00","exclude":false}`,
+		},
+		"overnight": {
+			Schedule: New(2200, 200, time.Friday),
+			Want:     `{"from":"22:00","to":"02:00","weekdays":["Fr"],"exclude":false}`,
+		},
+		"date range": {
+			Schedule: DateRange("2020-12-24", "2020-12-24", 1000, 1400),
+			Want:     `{"from":"10:00","to":"14:00","dateFrom":"2020-12-24","dateTo":"2020-12-24","exclude":false}`,
+		},
+		"exclude": {
+			Schedule: ExcludeDateRange(Annual(time.December, 25), Annual(time.December, 25)),
+			Want:     `{"from":"00:00","to":"00:00","dateFrom":"--12-25","dateTo":"--12-25","exclude":true}`,
+		},
+		"zone": {
+			Schedule: New(900, 1700).In(loc),
+			Want:     `{"from":"09:00","to":"17:00","exclude":false,"zone":"America/New_York"}`,
+		},
+		"recurrence": {
+			Schedule: New(900, 1700).Recur(NthWeekday(time.November, 4, time.Thursday, 1)),
+			Want:     `{"from":"09:00","to":"17:00","exclude":false,"recurrence":"nth=11/4Th+1"}`,
+		},
+		"zone and recurrence": {
+			Schedule: ExcludeDateRange("", "").In(loc).Recur(DaysOfMonth(1)),
+			Want:     `{"from":"00:00","to":"00:00","exclude":true,"zone":"America/New_York","recurrence":"monthly=1"}`,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			data, err := json.Marshal(Structured(tc.Schedule))
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Want, string(data))
+
+			var got Schedule
+			err = json.Unmarshal(data, &got)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Schedule.String(), got.String())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := map[string]struct {
+			Input string
+			Want  string
+		}{
+			"time": {
+				Input: `{"from":"8am","to":"18:00"}`,
+				Want:  "unable to unmarshal Schedule: invalid from time, 8am",
+			},
+			"hour": {
+				Input: `{"from":"08:00","to":"25:00"}`,
+				Want:  `unable to unmarshal Schedule: invalid Schedule, "1:::0800:2500::": to: invalid hour, 25`,
+			},
+			"weekday": {
+				Input: `{"from":"08:00","to":"18:00","weekdays":["Monday"]}`,
+				Want:  "unable to unmarshal Schedule: invalid weekday, Monday",
+			},
+			"zone": {
+				Input: `{"from":"08:00","to":"18:00","zone":"Mars/Olympus"}`,
+				Want:  `unable to unmarshal Schedule: invalid Schedule, "2:::0800:1800:::Mars/Olympus": zone: unknown zone, Mars/Olympus`,
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				var got Schedule
+				err := json.Unmarshal([]byte(tc.Input), &got)
+				assert.EqualError(t, err, tc.Want)
+			})
+		}
+	})
+}
+
+func TestStructuredSchedules(t *testing.T) {
+	ss := Schedules{New(800, 1200, time.Monday), ExcludeDateRange("2020-12-25", "2020-12-25")}
+
+	data, err := json.Marshal(StructuredSchedules(ss))
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"from":"08:00","to":"12:00","weekdays":["Mo"],"exclude":false},{"from":"00:00","to":"00:00","dateFrom":"2020-12-25","dateTo":"2020-12-25","exclude":true}]`, string(data))
+
+	var got Schedules
+	err = json.Unmarshal(data, &got)
+	assert.Nil(t, err)
+	assert.Equal(t, ss, got)
+
+	t.Run("mixed", func(t *testing.T) {
+		input := `["1:::0800:1200:Mo:",{"from":"13:00","to":"17:00","weekdays":["Mo"]}]`
+
+		var got Schedules
+		err := json.Unmarshal([]byte(input), &got)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1:::0800:1200:Mo:", "1:::1300:1700:Mo:"}, got.StringSlice())
+
+		var structured StructuredSchedules
+		err = json.Unmarshal([]byte(input), &structured)
+		assert.Nil(t, err)
+		assert.Equal(t, got, Schedules(structured))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		data, err := json.Marshal(StructuredSchedules(nil))
+		assert.Nil(t, err)
+		assert.Equal(t, `[]`, string(data))
+	})
+
+	t.Run("empty array", func(t *testing.T) {
+		got := Schedules{New(800, 1200)}
+		err := json.Unmarshal([]byte(`[]`), &got)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+}
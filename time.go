@@ -6,7 +6,13 @@ import (
 	"time"
 )
 
-const Midnight Time = 0
+const (
+	Midnight Time = 0
+
+	// EndOfDay marks midnight at the end of the day; Times beyond EndOfDay
+	// fall on the following day
+	EndOfDay Time = 2400
+)
 
 type Time int32
 
@@ -84,6 +90,52 @@ func (t Time) String() string {
 	return string(buffer)
 }
 
+// Align returns the time on the date of v, in the location of v, at which the
+// wall clock reads t.  Wall clock times skipped by a daylight saving
+// transition are moved forward to the end of the gap e.g. 02:30 becomes 03:00
+// in America/New_York on the spring forward date.  Wall clock times repeated
+// by a daylight saving transition resolve to their first occurrence.
 func (t Time) Align(v time.Time) time.Time {
-	return time.Date(v.Year(), v.Month(), v.Day(), t.Hour(), t.Minute(), 0, 0, v.Location())
+	wall := time.Date(v.Year(), v.Month(), v.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	return wallClock(wall, v.Location())
+}
+
+// wallClock returns the instant in loc whose wall clock matches the UTC fields
+// of wall
+func wallClock(wall time.Time, loc *time.Location) time.Time {
+	var (
+		unix      = wall.Unix()
+		before    = zoneOffset(unix-15*60*60, loc) // offsets range from -12h to +14h
+		after     = zoneOffset(unix+15*60*60, loc)
+		candidate = []int64{unix - before, unix - after}
+	)
+
+	if candidate[1] < candidate[0] {
+		candidate[0], candidate[1] = candidate[1], candidate[0]
+	}
+
+	// earliest valid instant wins; covers repeated wall clock times
+	for _, c := range candidate {
+		if c+zoneOffset(c, loc) == unix {
+			return time.Unix(c, 0).In(loc)
+		}
+	}
+
+	// wall clock falls in a gap; return the instant of the transition
+	lo, hi := candidate[0], candidate[1]
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if zoneOffset(mid, loc) == after {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return time.Unix(hi, 0).In(loc)
+}
+
+// zoneOffset returns the offset in seconds east of UTC of loc at the instant
+func zoneOffset(unix int64, loc *time.Location) int64 {
+	_, offset := time.Unix(unix, 0).In(loc).Zone()
+	return int64(offset)
 }
@@ -62,3 +62,99 @@ func TestTime_Add(t *testing.T) {
 		})
 	}
 }
+
+func TestTime_AlignDST(t *testing.T) {
+	testCases := map[string]struct {
+		Zone string
+		Date string
+		Time Time
+		Want string
+	}{
+		"new york - standard": {
+			Zone: "America/New_York",
+			Date: "2020-03-07",
+			Time: 230,
+			Want: "2020-03-07T02:30:00-05:00",
+		},
+		"new york - spring forward gap": {
+			Zone: "America/New_York",
+			Date: "2020-03-08",
+			Time: 230,
+			Want: "2020-03-08T03:00:00-04:00",
+		},
+		"new york - spring forward after gap": {
+			Zone: "America/New_York",
+			Date: "2020-03-08",
+			Time: 300,
+			Want: "2020-03-08T03:00:00-04:00",
+		},
+		"new york - fall back first occurrence": {
+			Zone: "America/New_York",
+			Date: "2020-11-01",
+			Time: 130,
+			Want: "2020-11-01T01:30:00-04:00",
+		},
+		"new york - fall back after overlap": {
+			Zone: "America/New_York",
+			Date: "2020-11-01",
+			Time: 200,
+			Want: "2020-11-01T02:00:00-05:00",
+		},
+		"new york - overnight": {
+			Zone: "America/New_York",
+			Date: "2020-03-07",
+			Time: 2630,
+			Want: "2020-03-08T03:00:00-04:00",
+		},
+		"london - spring forward gap": {
+			Zone: "Europe/London",
+			Date: "2020-03-29",
+			Time: 130,
+			Want: "2020-03-29T02:00:00+01:00",
+		},
+		"london - fall back first occurrence": {
+			Zone: "Europe/London",
+			Date: "2020-10-25",
+			Time: 130,
+			Want: "2020-10-25T01:30:00+01:00",
+		},
+		"london - midnight": {
+			Zone: "Europe/London",
+			Date: "2020-10-25",
+			Time: Midnight,
+			Want: "2020-10-25T00:00:00+01:00",
+		},
+		"lord howe - spring forward gap": {
+			Zone: "Australia/Lord_Howe",
+			Date: "2020-10-04",
+			Time: 215,
+			Want: "2020-10-04T02:30:00+11:00",
+		},
+		"lord howe - fall back first occurrence": {
+			Zone: "Australia/Lord_Howe",
+			Date: "2020-04-05",
+			Time: 145,
+			Want: "2020-04-05T01:45:00+11:00",
+		},
+		"lord howe - fall back after overlap": {
+			Zone: "Australia/Lord_Howe",
+			Date: "2020-04-05",
+			Time: 200,
+			Want: "2020-04-05T02:00:00+10:30",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			loc, err := time.LoadLocation(tc.Zone)
+			assert.Nil(t, err)
+
+			date, err := time.ParseInLocation("2006-01-02", tc.Date, loc)
+			assert.Nil(t, err)
+
+			got := tc.Time.Align(date)
+			assert.Equal(t, tc.Want, got.Format(time.RFC3339))
+			assert.Equal(t, loc, got.Location())
+		})
+	}
+}